@@ -0,0 +1,195 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/superfly/fly-go/wg"
+)
+
+type echoNet struct {
+	addr   string
+	closed chan string
+	org    string
+}
+
+func (n echoNet) Close() error {
+	if n.closed != nil {
+		n.closed <- n.org
+	}
+	return nil
+}
+
+func (n echoNet) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, "tcp", n.addr)
+}
+
+func TestAgent(t *testing.T) {
+	echo, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer echo.Close()
+	go func() {
+		for {
+			conn, err := echo.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	var mu sync.Mutex
+	var established []string
+	closed := make(chan string, 10)
+	hanging := make(chan struct{})
+	server := NewServer(func(ctx context.Context, org string) (*wg.Tunnel, error) {
+		switch org {
+		case "broken":
+			return nil, errors.New("no peer for broken")
+		case "hanging":
+			close(hanging)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		mu.Lock()
+		established = append(established, org)
+		mu.Unlock()
+		return wg.Connect(ctx, &wg.Config{}, func(context.Context, *wg.Config) (wg.Net, error) {
+			return echoNet{addr: echo.Addr().String(), closed: closed, org: org}, nil
+		})
+	})
+
+	path := filepath.Join(t.TempDir(), "agent.sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	serving := make(chan error, 1)
+	go func() { serving <- server.Serve(ctx, l) }()
+
+	client := NewClient(path)
+
+	// An organization whose tunnel never comes up doesn't hold up others.
+	hangCtx, hangCancel := context.WithCancel(ctx)
+	defer hangCancel()
+	go client.Establish(hangCtx, "hanging")
+	<-hanging
+
+	if version, err := client.Ping(ctx); err != nil || version != ProtocolVersion {
+		t.Errorf("ping, got '%v' (%v), want '%v'", version, err, ProtocolVersion)
+	}
+
+	if err := client.Establish(ctx, "personal"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Establish(ctx, "broken"); err == nil || err.Error() != "agent: no peer for broken" {
+		t.Errorf("establish, got '%v', want 'agent: no peer for broken'", err)
+	}
+
+	conn, err := client.DialContext(ctx, "personal", "[fdaa::3]:22")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "hello" {
+		t.Errorf("echo, got '%s', want 'hello'", buf)
+	}
+
+	// A client that half-closes still gets the reply.
+	conn, err = client.DialContext(ctx, "personal", "[fdaa::3]:22")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Write([]byte("bye"))
+	conn.(interface{ CloseWrite() error }).CloseWrite()
+	reply, err := io.ReadAll(conn)
+	if err != nil || string(reply) != "bye" {
+		t.Errorf("half-close, got '%s' (%v), want 'bye'", reply, err)
+	}
+
+	mu.Lock()
+	if len(established) != 1 {
+		t.Errorf("tunnels, got '%v', want one for 'personal'", established)
+	}
+	mu.Unlock()
+
+	cancel()
+	<-serving
+	select {
+	case org := <-closed:
+		if org != "personal" {
+			t.Errorf("closed tunnel, got '%v', want 'personal'", org)
+		}
+	default:
+		t.Error("tunnel was not closed when Serve returned")
+	}
+}
+
+func TestAgentEstablishTimeout(t *testing.T) {
+	attempts := make(chan struct{}, 10)
+	server := NewServer(func(ctx context.Context, org string) (*wg.Tunnel, error) {
+		attempts <- struct{}{}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	server.EstablishTimeout = 50 * time.Millisecond
+
+	path := filepath.Join(t.TempDir(), "agent.sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	serving := make(chan error, 1)
+	go func() { serving <- server.Serve(ctx, l) }()
+
+	client := NewClient(path)
+
+	// Requests waiting on the hung establishment all fail once it times
+	// out, rather than waiting for Serve to stop.
+	errs := make(chan error, 2)
+	for i := 0; i < cap(errs); i++ {
+		go func() { errs <- client.Establish(ctx, "hanging") }()
+	}
+	for i := 0; i < cap(errs); i++ {
+		if err := <-errs; err == nil || !strings.Contains(err.Error(), "timed out bringing up tunnel for hanging") {
+			t.Errorf("got '%v', want a timeout", err)
+		}
+	}
+
+	// The next request tries again.
+	before := len(attempts)
+	client.Establish(ctx, "hanging")
+	if got := len(attempts); got != before+1 {
+		t.Errorf("attempts, got '%v', want '%v'", got, before+1)
+	}
+
+	cancel()
+	<-serving
+}
@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// Client sends requests to a Server listening on a unix socket.
+type Client struct {
+	path   string
+	dialer net.Dialer
+}
+
+func NewClient(socketPath string) *Client {
+	return &Client{path: socketPath}
+}
+
+// Ping checks that the agent is running and returns its protocol version.
+func (c *Client) Ping(ctx context.Context) (string, error) {
+	resp, err := c.do(ctx, cmdPing)
+	if err != nil {
+		return "", err
+	}
+	if len(resp) != 1 {
+		return "", fmt.Errorf("agent: malformed ping response %q", resp)
+	}
+	return resp[0], nil
+}
+
+// Establish brings up the organization's tunnel, if it isn't already.
+func (c *Client) Establish(ctx context.Context, org string) error {
+	_, err := c.do(ctx, cmdEstablish, org)
+	return err
+}
+
+// Resolve looks up host with the organization's internal DNS.
+func (c *Client) Resolve(ctx context.Context, org, host string) ([]string, error) {
+	return c.do(ctx, cmdResolve, org, host)
+}
+
+// Instances lists the machines of app.
+func (c *Client) Instances(ctx context.Context, org, app string) ([]Instance, error) {
+	resp, err := c.do(ctx, cmdInstances, org, app)
+	if err != nil {
+		return nil, err
+	}
+
+	instances := make([]Instance, 0, len(resp))
+	for _, vm := range resp {
+		id, region, _ := strings.Cut(vm, "@")
+		instances = append(instances, Instance{ID: id, Region: region})
+	}
+	return instances, nil
+}
+
+// DialContext connects to address, a host and port on the organization's
+// private network, through the agent's tunnel.
+func (c *Client) DialContext(ctx context.Context, org, address string) (net.Conn, error) {
+	conn, r, resp, err := c.request(ctx, cmdConnect, org, address)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(resp); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &bufferedConn{Conn: conn, r: r}, nil
+}
+
+func (c *Client) do(ctx context.Context, fields ...string) ([]string, error) {
+	conn, _, resp, err := c.request(ctx, fields...)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+	return resp[1:], nil
+}
+
+func (c *Client) request(ctx context.Context, fields ...string) (net.Conn, *bufio.Reader, []string, error) {
+	conn, err := c.dialer.DialContext(ctx, "unix", c.path)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("agent: can't connect to %s: %w", c.path, err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if err := writeLine(conn, fields...); err != nil {
+		conn.Close()
+		return nil, nil, nil, err
+	}
+
+	r := bufio.NewReader(conn)
+	resp, err := readLine(r)
+	if err != nil {
+		conn.Close()
+		return nil, nil, nil, fmt.Errorf("agent: failed to read response: %w", err)
+	}
+
+	conn.SetDeadline(time.Time{})
+	return conn, r, resp, nil
+}
+
+func checkResponse(resp []string) error {
+	switch {
+	case len(resp) > 0 && resp[0] == "ok":
+		return nil
+	case len(resp) > 0 && resp[0] == "err":
+		return errors.New("agent: " + strings.Join(resp[1:], " "))
+	default:
+		return fmt.Errorf("agent: malformed response %q", resp)
+	}
+}
@@ -0,0 +1,90 @@
+// Package agent lets several processes on one machine share a single
+// WireGuard tunnel per organization. A long-lived Server owns the tunnels
+// and serves requests from Clients over a unix socket.
+//
+// Each request is a single line on its own connection, a command followed
+// by space-separated arguments:
+//
+//	ping
+//	establish <org>
+//	resolve <org> <host>
+//	instances <org> <app>
+//	connect <org> <address>
+//
+// The server answers with a line starting with "ok", followed by the
+// result, or "err" followed by an error message. After a successful
+// connect, the connection carries the proxied stream.
+package agent
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ProtocolVersion is reported by ping, so clients can detect a stale agent.
+const ProtocolVersion = "1"
+
+const (
+	cmdPing      = "ping"
+	cmdEstablish = "establish"
+	cmdResolve   = "resolve"
+	cmdInstances = "instances"
+	cmdConnect   = "connect"
+)
+
+const maxLineLength = 64 * 1024
+
+// Instance is a machine of an app, as listed by the organization's
+// internal DNS.
+type Instance struct {
+	ID     string
+	Region string
+}
+
+func writeLine(w net.Conn, fields ...string) error {
+	for _, f := range fields {
+		if f == "" || strings.ContainsAny(f, " \t\r\n") {
+			return fmt.Errorf("agent: invalid argument '%s'", f)
+		}
+	}
+	_, err := fmt.Fprintf(w, "%s\n", strings.Join(fields, " "))
+	return err
+}
+
+func readLine(r *bufio.Reader) ([]string, error) {
+	var line []byte
+	for {
+		chunk, isPrefix, err := r.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, chunk...)
+		if len(line) > maxLineLength {
+			return nil, errors.New("agent: line too long")
+		}
+		if !isPrefix {
+			break
+		}
+	}
+	return strings.Fields(string(line)), nil
+}
+
+// bufferedConn reads through the buffer the request or response line was
+// read with, so bytes sent right after it aren't lost.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) { return c.r.Read(p) }
+
+// CloseWrite half-closes the underlying connection, when it supports it.
+func (c *bufferedConn) CloseWrite() error {
+	if cw, ok := c.Conn.(interface{ CloseWrite() error }); ok {
+		return cw.CloseWrite()
+	}
+	return nil
+}
@@ -0,0 +1,324 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/superfly/fly-go/wg"
+)
+
+// TunnelFunc brings up the tunnel for an organization, typically with
+// wg.ReestablishPeer and wg.Connect.
+type TunnelFunc func(ctx context.Context, org string) (*wg.Tunnel, error)
+
+// DefaultEstablishTimeout bounds bringing up a tunnel when the Server's
+// EstablishTimeout is unset.
+const DefaultEstablishTimeout = 30 * time.Second
+
+// Server owns one tunnel per organization, brought up on first use, and
+// serves agent requests. A tunnel that stops responding is closed and
+// brought up again on the next request for its organization.
+type Server struct {
+	// EstablishTimeout bounds each attempt to bring up an organization's
+	// tunnel, so a TunnelFunc that hangs fails the requests waiting on it
+	// and the next request tries again. Zero means
+	// DefaultEstablishTimeout.
+	EstablishTimeout time.Duration
+
+	tunnel TunnelFunc
+
+	mu      sync.Mutex
+	tunnels map[string]*orgTunnel
+}
+
+// orgTunnel is an organization's tunnel, or its establishment in progress
+// until ready is closed.
+type orgTunnel struct {
+	ready chan struct{}
+	t     *wg.Tunnel
+	err   error
+}
+
+func NewServer(tunnel TunnelFunc) *Server {
+	return &Server{
+		tunnel:  tunnel,
+		tunnels: map[string]*orgTunnel{},
+	}
+}
+
+// Serve handles connections accepted on l, usually a unix socket listener,
+// until ctx is done or l fails. The listener and the tunnels are closed
+// when Serve returns.
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	ctx, cancel := context.WithCancel(ctx)
+
+	var wg sync.WaitGroup
+	// Cancel first, so open connections are closed rather than waited on.
+	defer func() {
+		cancel()
+		wg.Wait()
+		s.closeTunnels()
+	}()
+
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.handle(ctx, conn)
+		}()
+	}
+}
+
+func (s *Server) handle(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	req, err := readLine(r)
+	if err != nil {
+		return
+	}
+	if len(req) == 0 {
+		s.fail(conn, errors.New("empty request"))
+		return
+	}
+
+	cmd, args := req[0], req[1:]
+	if err := checkArgs(cmd, args); err != nil {
+		s.fail(conn, err)
+		return
+	}
+
+	switch cmd {
+	case cmdPing:
+		writeLine(conn, "ok", ProtocolVersion)
+	case cmdEstablish:
+		if _, err := s.tunnelFor(ctx, args[0]); err != nil {
+			s.fail(conn, err)
+			return
+		}
+		writeLine(conn, "ok")
+	case cmdResolve:
+		s.resolve(ctx, conn, args[0], args[1])
+	case cmdInstances:
+		s.instances(ctx, conn, args[0], args[1])
+	case cmdConnect:
+		s.connect(ctx, &bufferedConn{Conn: conn, r: r}, args[0], args[1])
+	}
+}
+
+func checkArgs(cmd string, args []string) error {
+	want := map[string]int{
+		cmdPing:      0,
+		cmdEstablish: 1,
+		cmdResolve:   2,
+		cmdInstances: 2,
+		cmdConnect:   2,
+	}
+	n, ok := want[cmd]
+	switch {
+	case !ok:
+		return fmt.Errorf("unknown command '%s'", cmd)
+	case len(args) != n:
+		return fmt.Errorf("%s takes %d arguments, got %d", cmd, n, len(args))
+	}
+	return nil
+}
+
+func (s *Server) fail(conn net.Conn, err error) {
+	msg := strings.Join(strings.Fields(err.Error()), " ")
+	fmt.Fprintf(conn, "err %s\n", msg)
+}
+
+// tunnelFor returns the organization's tunnel, bringing it up if needed.
+// Concurrent requests for an organization share one establishment, and
+// don't hold up requests for other organizations.
+func (s *Server) tunnelFor(ctx context.Context, org string) (*wg.Tunnel, error) {
+	s.mu.Lock()
+	ot, ok := s.tunnels[org]
+	if !ok {
+		ot = &orgTunnel{ready: make(chan struct{})}
+		s.tunnels[org] = ot
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		ot.t, ot.err = s.establish(ctx, org)
+		if ot.err != nil {
+			s.mu.Lock()
+			if s.tunnels[org] == ot {
+				delete(s.tunnels, org)
+			}
+			s.mu.Unlock()
+		}
+		close(ot.ready)
+	}
+
+	select {
+	case <-ot.ready:
+		return ot.t, ot.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *Server) establish(ctx context.Context, org string) (*wg.Tunnel, error) {
+	timeout := s.EstablishTimeout
+	if timeout <= 0 {
+		timeout = DefaultEstablishTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	t, err := s.tunnel(ctx, org)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("timed out bringing up tunnel for %s after %s", org, timeout)
+	}
+	return t, err
+}
+
+// check evicts the organization's tunnel t when err, from using it, is a
+// timeout: the sign of a tunnel whose peer no longer answers. The next
+// request brings a fresh one up.
+func (s *Server) check(org string, t *wg.Tunnel, err error) {
+	var netErr net.Error
+	if !errors.Is(err, context.DeadlineExceeded) && !(errors.As(err, &netErr) && netErr.Timeout()) {
+		return
+	}
+
+	s.mu.Lock()
+	ot, ok := s.tunnels[org]
+	evict := ok && ot.t == t
+	if evict {
+		delete(s.tunnels, org)
+	}
+	s.mu.Unlock()
+
+	if evict {
+		t.Close()
+	}
+}
+
+func (s *Server) closeTunnels() {
+	s.mu.Lock()
+	tunnels := s.tunnels
+	s.tunnels = map[string]*orgTunnel{}
+	s.mu.Unlock()
+
+	for _, ot := range tunnels {
+		<-ot.ready
+		if ot.t != nil {
+			ot.t.Close()
+		}
+	}
+}
+
+func (s *Server) resolve(ctx context.Context, conn net.Conn, org, host string) {
+	t, err := s.tunnelFor(ctx, org)
+	if err != nil {
+		s.fail(conn, err)
+		return
+	}
+
+	addrs, err := t.Resolver().LookupHost(ctx, host)
+	if err != nil {
+		s.check(org, t, err)
+		s.fail(conn, err)
+		return
+	}
+	writeLine(conn, append([]string{"ok"}, addrs...)...)
+}
+
+func (s *Server) instances(ctx context.Context, conn net.Conn, org, app string) {
+	t, err := s.tunnelFor(ctx, org)
+	if err != nil {
+		s.fail(conn, err)
+		return
+	}
+
+	// vms.<app>.internal lists "<id> <region>" pairs, comma-separated.
+	records, err := t.Resolver().LookupTXT(ctx, fmt.Sprintf("vms.%s.internal", app))
+	if err != nil {
+		s.check(org, t, err)
+		s.fail(conn, err)
+		return
+	}
+
+	out := []string{"ok"}
+	for _, record := range records {
+		for _, vm := range strings.Split(record, ",") {
+			id, region, ok := strings.Cut(strings.TrimSpace(vm), " ")
+			if ok {
+				out = append(out, id+"@"+region)
+			}
+		}
+	}
+	writeLine(conn, out...)
+}
+
+func (s *Server) connect(ctx context.Context, conn net.Conn, org, address string) {
+	t, err := s.tunnelFor(ctx, org)
+	if err != nil {
+		s.fail(conn, err)
+		return
+	}
+
+	remote, err := t.DialContext(ctx, "tcp", address)
+	if err != nil {
+		s.check(org, t, err)
+		s.fail(conn, err)
+		return
+	}
+	defer remote.Close()
+
+	if err := writeLine(conn, "ok"); err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+		remote.Close()
+	}()
+
+	// Each direction is half-closed when its source is done, so the other
+	// can still deliver, say, the reply to a request the client finished.
+	done := make(chan struct{}, 2)
+	pipe := func(dst, src net.Conn) {
+		io.Copy(dst, src)
+		if cw, ok := dst.(interface{ CloseWrite() error }); ok {
+			cw.CloseWrite()
+		}
+		done <- struct{}{}
+	}
+	go pipe(remote, conn)
+	go pipe(conn, remote)
+
+	<-done
+	<-done
+}
@@ -9,3 +9,14 @@ var ErrNotFound = errors.New("Not Found")
 var ErrUnknown = errors.New("An unknown server error occurred, please try again")
 
 var ErrNoAuthToken = errors.New("No access token available. Please login with 'flyctl auth login'")
+
+// ErrTwoFactorRequired - Error returned by GetAccessToken when the account
+// needs a one-time password and none, or an invalid one, was given
+var ErrTwoFactorRequired = errors.New("A one-time password is required")
+
+// ErrInvalidCredentials - Error returned by GetAccessToken when the email and
+// password are rejected
+var ErrInvalidCredentials = errors.New("Incorrect email and password combination")
+
+// ErrRateLimited - Error returned when too many requests have been made
+var ErrRateLimited = errors.New("Too many requests, please try again later")
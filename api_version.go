@@ -0,0 +1,63 @@
+package fly
+
+import (
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// ClientVersionHeader carries the version of this package on every request,
+// so the API can tell which clients still depend on behavior it's retiring.
+const ClientVersionHeader = "Fly-Go-Version"
+
+// MinimumClientVersionHeader is sent by the API when it requires a newer
+// client than the one making the request.
+const MinimumClientVersionHeader = "Fly-Minimum-Client-Version"
+
+// APINotice reports deprecation headers the API sent with a response.
+type APINotice struct {
+	URL *url.URL
+
+	// Deprecation is the raw Deprecation header, set when the endpoint is
+	// deprecated.
+	Deprecation string
+
+	// Sunset is when the endpoint will stop working, or zero if unknown.
+	Sunset time.Time
+
+	// MinimumVersion is the oldest client version the API will keep
+	// serving.
+	MinimumVersion string
+}
+
+// apiNoticeFromResponse returns the notice carried by resp, if any.
+func apiNoticeFromResponse(resp *http.Response) (APINotice, bool) {
+	notice := APINotice{
+		Deprecation:    resp.Header.Get("Deprecation"),
+		MinimumVersion: resp.Header.Get(MinimumClientVersionHeader),
+	}
+	if resp.Request != nil {
+		notice.URL = resp.Request.URL
+	}
+	if sunset := resp.Header.Get("Sunset"); sunset != "" {
+		notice.Sunset, _ = http.ParseTime(sunset)
+	}
+
+	ok := notice.Deprecation != "" || notice.MinimumVersion != "" || !notice.Sunset.IsZero()
+	return notice, ok
+}
+
+var clientVersion = sync.OnceValue(func() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == "github.com/superfly/fly-go" {
+			return dep.Version
+		}
+	}
+	return "devel"
+})
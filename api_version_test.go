@@ -0,0 +1,29 @@
+package fly
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAPINoticeFromResponse(t *testing.T) {
+	resp := &http.Response{Header: http.Header{}}
+	if _, ok := apiNoticeFromResponse(resp); ok {
+		t.Error("want no notice without deprecation headers")
+	}
+
+	resp.Header.Set("Deprecation", "true")
+	resp.Header.Set("Sunset", "Sat, 31 Oct 2026 23:59:59 GMT")
+	resp.Header.Set(MinimumClientVersionHeader, "v0.2.0")
+
+	notice, ok := apiNoticeFromResponse(resp)
+	if !ok {
+		t.Fatal("want a notice")
+	}
+	if want := time.Date(2026, 10, 31, 23, 59, 59, 0, time.UTC); !notice.Sunset.Equal(want) {
+		t.Errorf("got '%v', want '%v'", notice.Sunset, want)
+	}
+	if notice.Deprecation != "true" || notice.MinimumVersion != "v0.2.0" {
+		t.Errorf("got '%+v'", notice)
+	}
+}
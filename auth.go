@@ -3,8 +3,26 @@ package fly
 import (
 	"context"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
+var authTimeout atomic.Int64
+
+// SetAuthTimeout bounds each request made by the login helpers, such as
+// GetAccessToken and StartCLISessionWithContext, in addition to any deadline
+// on the context passed to them. Zero, the default, means no timeout.
+func SetAuthTimeout(d time.Duration) {
+	authTimeout.Store(int64(d))
+}
+
+func authContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if d := time.Duration(authTimeout.Load()); d > 0 {
+		return context.WithTimeout(ctx, d)
+	}
+	return context.WithCancel(ctx)
+}
+
 type CLISessionAuth struct {
 	CLISession
 }
@@ -12,12 +30,30 @@ type CLISessionAuth struct {
 // StartCLISessionWebAuth starts a session with the platform via web auth
 func StartCLISessionWebAuth(machineName string, signup bool) (CLISession, error) {
 
-	return StartCLISession(machineName, map[string]interface{}{
+	return StartCLISessionWebAuthWithContext(context.Background(), machineName, signup)
+}
+
+// StartCLISessionWebAuthWithContext starts a session with the platform via
+// web auth, giving up when ctx is done or the auth timeout passes
+func StartCLISessionWebAuthWithContext(ctx context.Context, machineName string, signup bool) (CLISession, error) {
+	return StartCLISessionWithContext(ctx, machineName, map[string]interface{}{
 		"signup": signup,
 		"target": "auth",
 	})
 }
 
+// StartCLISessionWebAuthWithPeer starts a session with the platform via web
+// auth that also creates a WireGuard peer for peer.Pubkey, so a tool can log
+// in and connect to the private network in one step. Poll the session with
+// GetCLISessionState for the token and the peer's tunnel details.
+func StartCLISessionWebAuthWithPeer(ctx context.Context, machineName string, signup bool, peer CLISessionPeer) (CLISession, error) {
+	return StartCLISessionWithContext(ctx, machineName, map[string]interface{}{
+		"signup":         signup,
+		"target":         "auth",
+		"wireguard_peer": peer,
+	})
+}
+
 // GetAccessTokenForCLISession Obtains the access token for the session
 func GetAccessTokenForCLISession(ctx context.Context, id string) (string, error) {
 	val, err := GetCLISessionState(ctx, id)
@@ -0,0 +1,89 @@
+package fly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const defaultBulkConcurrency = 4
+
+// AppResult is the outcome of a bulk operation on a single app.
+type AppResult[T any] struct {
+	AppName string
+	Value   T
+	Err     error
+}
+
+// AppResults are the outcomes of a bulk operation, in the order the apps were
+// given.
+type AppResults[T any] []AppResult[T]
+
+// Failed returns the results whose operation failed.
+func (r AppResults[T]) Failed() AppResults[T] {
+	var failed AppResults[T]
+	for _, res := range r {
+		if res.Err != nil {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// Err joins the errors of failed operations, each prefixed with its app
+// name, or returns nil if every operation succeeded.
+func (r AppResults[T]) Err() error {
+	var errs []error
+	for _, res := range r.Failed() {
+		errs = append(errs, fmt.Errorf("%s: %w", res.AppName, res.Err))
+	}
+	return errors.Join(errs...)
+}
+
+// ForEachApp calls fn once for every app in appNames, running at most
+// concurrency calls at a time (4 if concurrency is less than 1). A failure
+// for one app doesn't stop the others; check the returned results, or their
+// Err, for partial failures. Apps not yet started when ctx is canceled fail
+// with the context's error.
+//
+// Use it to fan out anything that takes an app name, such as a deploy with
+// the deploy package or a scale with a per-app flaps client.
+func ForEachApp[T any](ctx context.Context, appNames []string, concurrency int, fn func(ctx context.Context, appName string) (T, error)) AppResults[T] {
+	if concurrency < 1 {
+		concurrency = defaultBulkConcurrency
+	}
+
+	results := make(AppResults[T], len(appNames))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, appName := range appNames {
+		results[i].AppName = appName
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(res *AppResult[T]) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			res.Value, res.Err = fn(ctx, res.AppName)
+		}(&results[i])
+	}
+
+	wg.Wait()
+	return results
+}
+
+// SetSecretsForApps sets the same secrets on every app in appNames, with at
+// most concurrency updates in flight.
+func (c *Client) SetSecretsForApps(ctx context.Context, appNames []string, secrets map[string]string, concurrency int) AppResults[*Release] {
+	return ForEachApp(ctx, appNames, concurrency, func(ctx context.Context, appName string) (*Release, error) {
+		return c.SetSecrets(ctx, appName, secrets)
+	})
+}
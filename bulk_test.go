@@ -0,0 +1,47 @@
+package fly
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestForEachApp(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	boom := errors.New("boom")
+
+	results := ForEachApp(context.Background(), []string{"a", "b", "c", "d", "e"}, 2, func(ctx context.Context, appName string) (string, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+
+		if appName == "c" {
+			return "", boom
+		}
+		return "done " + appName, nil
+	})
+
+	if got := maxRunning.Load(); got > 2 {
+		t.Errorf("concurrency, got '%v', want at most '2'", got)
+	}
+	if got, want := len(results), 5; got != want {
+		t.Fatalf("results, got '%v', want '%v'", got, want)
+	}
+	if got, want := results[4].Value, "done e"; got != want {
+		t.Errorf("value, got '%v', want '%v'", got, want)
+	}
+
+	failed := results.Failed()
+	if len(failed) != 1 || failed[0].AppName != "c" {
+		t.Errorf("failed, got '%v', want app 'c'", failed)
+	}
+	if err := results.Err(); !errors.Is(err, boom) || err.Error() != "c: boom" {
+		t.Errorf("err, got '%v', want 'c: boom'", err)
+	}
+}
@@ -13,12 +13,35 @@ type CLISession struct {
 	URL         string                 `json:"auth_url,omitempty"`
 	AccessToken string                 `json:"access_token,omitempty"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+
+	// WireGuardPeer is the tunnel created for the public key submitted with
+	// StartCLISessionWebAuthWithPeer, once the session is authorized.
+	WireGuardPeer *CreatedWireGuardPeer `json:"wireguard_peer,omitempty"`
+}
+
+// CLISessionPeer asks for a WireGuard peer to be created for the public key
+// when the user completes web auth, in the organization they choose.
+type CLISessionPeer struct {
+	Pubkey string `json:"pubkey"`
+	Name   string `json:"name,omitempty"`
+	Region string `json:"region,omitempty"`
 }
 
 // StartCLISession starts a session with the platform via web
 func StartCLISession(sessionName string, args map[string]interface{}) (CLISession, error) {
+	return StartCLISessionWithContext(context.Background(), sessionName, args)
+}
+
+// StartCLISessionWithContext starts a session with the platform via web,
+// giving up when ctx is done or the auth timeout passes. If the platform
+// refuses, the error is an *ApiError wrapping ErrUnknown, so compare it with
+// errors.Is rather than ==.
+func StartCLISessionWithContext(ctx context.Context, sessionName string, args map[string]interface{}) (CLISession, error) {
 	var result CLISession
 
+	ctx, cancel := authContext(ctx)
+	defer cancel()
+
 	if args == nil {
 		args = make(map[string]interface{})
 	}
@@ -28,26 +51,43 @@ func StartCLISession(sessionName string, args map[string]interface{}) (CLISessio
 
 	url := fmt.Sprintf("%s/api/v1/cli_sessions", baseURL)
 
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(postData))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(postData))
 	if err != nil {
 		return result, err
 	}
+	req.Header.Set("Content-Type", "application/json")
 
-	if resp.StatusCode != 201 {
-		return result, ErrUnknown
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return result, err
 	}
 
 	defer resp.Body.Close() //skipcq: GO-S2307
 
+	if resp.StatusCode != 201 {
+		apiErr := ErrorFromResp(resp)
+		if apiErr.WrappedError == nil {
+			apiErr.WrappedError = ErrUnknown
+		}
+		return result, apiErr
+	}
+
 	json.NewDecoder(resp.Body).Decode(&result)
 
 	return result, nil
 }
 
+// GetCLISessionState returns the current state of a session. It returns
+// ErrNotFound itself while the session has not been authorized, so pollers
+// can keep comparing against it. Other failures are an *ApiError wrapping
+// ErrUnknown.
 func GetCLISessionState(ctx context.Context, id string) (CLISession, error) {
 
 	var value CLISession
 
+	ctx, cancel := authContext(ctx)
+	defer cancel()
+
 	url := fmt.Sprintf("%s/api/v1/cli_sessions/%s", baseURL, id)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
@@ -70,6 +110,10 @@ func GetCLISessionState(ctx context.Context, id string) (CLISession, error) {
 	case http.StatusNotFound:
 		return value, ErrNotFound
 	default:
-		return value, ErrUnknown
+		apiErr := ErrorFromResp(res)
+		if apiErr.WrappedError == nil {
+			apiErr.WrappedError = ErrUnknown
+		}
+		return value, apiErr
 	}
 }
@@ -4,8 +4,8 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"regexp"
@@ -19,6 +19,7 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
 )
 
 var (
@@ -39,7 +40,7 @@ func actionFromCtx(ctx context.Context) string {
 	if action != nil {
 		return action.(string)
 	}
-	return "unknown_actiom"
+	return "unknown_action"
 }
 
 // SetBaseURL - Sets the base URL for the API
@@ -71,6 +72,22 @@ type Client struct {
 	GenqClient genq.Client
 	tokens     *tokens.Tokens
 	logger     Logger
+	inflight   *singleflight.Group
+	retainRaw  bool
+	transport  *Transport
+	orgIDs     orgIDCache
+
+	maxResponseBytes int64
+	maxSecretsSize   int
+}
+
+// RateLimit returns the rate limit state the API reported with the most
+// recent response that carried one, so callers can pace themselves.
+func (c *Client) RateLimit() (RateLimitInfo, bool) {
+	if c.transport == nil {
+		return RateLimitInfo{}, false
+	}
+	return c.transport.RateLimit()
 }
 
 func (c *Client) Authenticated() bool {
@@ -97,6 +114,49 @@ type ClientOptions struct {
 	Logger           Logger
 	EnableDebugTrace *bool
 	Transport        *Transport
+
+	// GraphQLPath is the path of the GraphQL endpoint under BaseURL.
+	// Defaults to "/graphql".
+	GraphQLPath string
+
+	// GraphQLURL, when set, is the full URL of the GraphQL endpoint, for
+	// clients behind gateways that don't mirror the API's layout. It takes
+	// precedence over BaseURL and GraphQLPath.
+	GraphQLURL string
+
+	// DeduplicateQueries collapses identical queries issued concurrently,
+	// for example by goroutines looking up the same app, into a single
+	// request. Every caller gets its own copy of the response and can
+	// still give up on it through its own context.
+	DeduplicateQueries bool
+
+	// OnAPINotice, when set, is called for every response that carries
+	// deprecation or minimum-version headers, so callers can warn before an
+	// API transition breaks them. It's ignored if Transport sets its own.
+	OnAPINotice func(APINotice)
+
+	// ConnectionPool, when set, gives the client its own connection pool
+	// with these settings instead of sharing the default transport. It's
+	// ignored if Transport sets an UnderlyingTransport.
+	ConnectionPool *ConnectionPoolOptions
+
+	// RetainRawResponses keeps the JSON data of every GraphQL response,
+	// available from Query.Raw, for logging or reading fields the typed
+	// structs don't model.
+	RetainRawResponses bool
+
+	// MaxResponseBytes limits the size of log pages, from GetAppLogs and
+	// StreamAppLogs, that the client will read. Zero means no limit.
+	MaxResponseBytes int64
+
+	// MaxSecretsSize, if set, makes SetSecrets reject batches whose names
+	// and values add up to more than this many bytes before sending them.
+	// The API enforces its own limit; zero leaves the check to it.
+	MaxSecretsSize int
+
+	// RetryPolicy, if set, is used by this client instead of the policy
+	// set with SetRetryPolicy. Zero fields keep their defaults.
+	RetryPolicy *RetryPolicy
 }
 
 func (opts ClientOptions) tokens() *tokens.Tokens {
@@ -107,9 +167,25 @@ func (opts ClientOptions) tokens() *tokens.Tokens {
 	return opts.Tokens
 }
 
+func (opts ClientOptions) graphQLURL() string {
+	if opts.GraphQLURL != "" {
+		return opts.GraphQLURL
+	}
+
+	path := opts.GraphQLPath
+	if path == "" {
+		path = "/graphql"
+	}
+	return strings.TrimSuffix(opts.BaseURL, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
 func (t *Transport) setDefaults(opts *ClientOptions) {
 	if t.UnderlyingTransport == nil {
-		t.UnderlyingTransport = defaultTransport
+		if opts.ConnectionPool != nil {
+			t.UnderlyingTransport = NewPooledTransport(*opts.ConnectionPool)
+		} else {
+			t.UnderlyingTransport = defaultTransport
+		}
 	}
 	if t.Tokens == nil && t.Token == "" {
 		t.Tokens = opts.tokens()
@@ -117,6 +193,9 @@ func (t *Transport) setDefaults(opts *ClientOptions) {
 	if t.UserAgent == "" {
 		t.UserAgent = fmt.Sprintf("%s/%s", opts.Name, opts.Version)
 	}
+	if t.OnAPINotice == nil {
+		t.OnAPINotice = opts.OnAPINotice
+	}
 	if opts.EnableDebugTrace != nil {
 		t.EnableDebugTrace = *opts.EnableDebugTrace
 	} else {
@@ -136,12 +215,31 @@ func NewClientFromOptions(opts ClientOptions) *Client {
 	}
 	transport.setDefaults(&opts)
 
-	httpClient, _ := NewHTTPClient(opts.Logger, transport)
-	url := fmt.Sprintf("%s/graphql", opts.BaseURL)
+	policy := currentRetryPolicy()
+	if opts.RetryPolicy != nil {
+		policy = opts.RetryPolicy.withDefaults()
+	}
+	httpClient, _ := newHTTPClient(opts.Logger, transport, policy)
+	url := opts.graphQLURL()
 	client := graphql.NewClient(url, graphql.WithHTTPClient(httpClient))
 	genqClient := genq.NewClient(url, httpClient)
 
-	return &Client{httpClient, client, genqClient, opts.tokens(), opts.Logger}
+	c := &Client{
+		httpClient: httpClient,
+		client:     client,
+		GenqClient: genqClient,
+		tokens:     opts.tokens(),
+		logger:     opts.Logger,
+		retainRaw:  opts.RetainRawResponses,
+		transport:  transport,
+
+		maxResponseBytes: opts.MaxResponseBytes,
+		maxSecretsSize:   opts.MaxSecretsSize,
+	}
+	if opts.DeduplicateQueries {
+		c.inflight = new(singleflight.Group)
+	}
+	return c
 }
 
 // NewRequest - creates a new GraphQL request
@@ -195,8 +293,14 @@ func (c *Client) RunWithContext(ctx context.Context, req *graphql.Request) (Quer
 		}()
 	}
 
-	var resp Query
-	err := c.client.Run(ctx, req, &resp)
+	if err := validateRequestVars(req); err != nil {
+		return Query{}, newOperationError(actionFromCtx(ctx), req.Vars(), err)
+	}
+
+	resp, err := c.run(ctx, req)
+	if err != nil {
+		err = newOperationError(actionFromCtx(ctx), req.Vars(), err)
+	}
 
 	if resp.Errors != nil {
 		span.RecordError(fmt.Errorf(c.getErrorFromErrors(resp.Errors)))
@@ -210,6 +314,19 @@ func (c *Client) RunWithContext(ctx context.Context, req *graphql.Request) (Quer
 	return resp, err
 }
 
+// RunInto runs a GraphQL request and decodes its data into v rather than
+// Query. Use it, together with fragments such as ReleaseFields, to select
+// fields the typed methods don't.
+func (c *Client) RunInto(ctx context.Context, req *graphql.Request, v any) error {
+	if err := validateRequestVars(req); err != nil {
+		return newOperationError(actionFromCtx(ctx), req.Vars(), err)
+	}
+	if err := c.client.Run(ctx, req, v); err != nil {
+		return newOperationError(actionFromCtx(ctx), req.Vars(), err)
+	}
+	return nil
+}
+
 var compactPattern = regexp.MustCompile(`\s+`)
 
 func compactQueryString(q string) string {
@@ -217,7 +334,10 @@ func compactQueryString(q string) string {
 	return compactPattern.ReplaceAllString(q, " ")
 }
 
-// GetAccessToken - uses email, password and possible otp to get token
+// GetAccessToken - uses email, password and possible otp to get token.
+// Failures are *ApiError values wrapping ErrTwoFactorRequired,
+// ErrInvalidCredentials, ErrRateLimited or ErrUnknown; use errors.Is to tell
+// them apart.
 func GetAccessToken(ctx context.Context, email, password, otp string) (token string, err error) {
 	var postData bytes.Buffer
 	if err = json.NewEncoder(&postData).Encode(map[string]interface{}{
@@ -232,6 +352,9 @@ func GetAccessToken(ctx context.Context, email, password, otp string) (token str
 		return
 	}
 
+	ctx, cancel := authContext(ctx)
+	defer cancel()
+
 	url := fmt.Sprintf("%s/api/v1/sessions", baseURL)
 
 	var req *http.Request
@@ -251,17 +374,21 @@ func GetAccessToken(ctx context.Context, email, password, otp string) (token str
 		}
 	}()
 
-	switch {
-	case res.StatusCode >= http.StatusInternalServerError:
-		err = errors.New("An unknown server error occurred, please try again")
-	case res.StatusCode >= http.StatusBadRequest:
-		err = errors.New("Incorrect email and password combination")
-	default:
-		var result map[string]map[string]map[string]string
-
-		if err = json.NewDecoder(res.Body).Decode(&result); err == nil {
-			token = result["data"]["attributes"]["access_token"]
+	if res.StatusCode >= http.StatusBadRequest {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBodyBytes))
+		apiErr := accessTokenError(res.StatusCode, body)
+		apiErr.RequestID = res.Header.Get("Fly-Request-Id")
+		if info, ok := rateLimitFromHeader(res.Header, time.Now()); ok {
+			apiErr.RateLimit = &info
 		}
+		err = apiErr
+		return
+	}
+
+	var result map[string]map[string]map[string]string
+
+	if err = json.NewDecoder(res.Body).Decode(&result); err == nil {
+		token = result["data"]["attributes"]["access_token"]
 	}
 
 	return
@@ -273,16 +400,39 @@ type Transport struct {
 	Token               string // deprecated
 	Tokens              *tokens.Tokens
 	EnableDebugTrace    bool
+
+	// OnAPINotice, when set, is called for every response that carries
+	// deprecation or minimum-version headers.
+	OnAPINotice func(APINotice)
+
+	rateLimit rateLimitState
+}
+
+// RateLimit returns the rate limit state reported with the most recent
+// response that carried one.
+func (t *Transport) RateLimit() (RateLimitInfo, bool) {
+	return t.rateLimit.get()
 }
 
 func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
 	t.addAuthorization(req)
 
 	req.Header.Set("User-Agent", t.UserAgent)
+	req.Header.Set(ClientVersionHeader, clientVersion())
 	if t.EnableDebugTrace {
 		req.Header.Set("Fly-Force-Trace", "true")
 	}
-	return t.UnderlyingTransport.RoundTrip(req)
+
+	resp, err := t.UnderlyingTransport.RoundTrip(req)
+	if err == nil {
+		t.rateLimit.observe(resp)
+	}
+	if err == nil && t.OnAPINotice != nil {
+		if notice, ok := apiNoticeFromResponse(resp); ok {
+			t.OnAPINotice(notice)
+		}
+	}
+	return resp, err
 }
 
 func (t *Transport) tokens() *tokens.Tokens {
@@ -0,0 +1,109 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/fly-go/flaps"
+)
+
+// Canary is a machine running the new configuration alongside the machines
+// it will replace. Progressive-delivery controllers can check its health
+// for as long as they like before promoting or aborting the deployment.
+type Canary struct {
+	Machine *fly.Machine
+
+	d *deployer
+}
+
+// StartCanary launches a canary for opts.Machines, based on the first of
+// them, and waits for it to start. It doesn't wait for health checks; see
+// Canary.Health.
+func StartCanary(ctx context.Context, client *flaps.Client, opts Options) (*Canary, error) {
+	if len(opts.Machines) == 0 {
+		return nil, errors.New("deploy: no machines to deploy")
+	}
+
+	d, err := newDeployer(client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	base := opts.Machines[0]
+	m, err := client.Launch(ctx, fly.LaunchMachineInput{
+		Region: base.Region,
+		Config: opts.Config(base),
+	})
+	if err != nil {
+		return nil, d.fail(base.ID, err)
+	}
+	d.emit(Event{Type: EventMachineLaunched, MachineID: m.ID})
+
+	c := &Canary{Machine: m, d: d}
+	if err := d.waitStarted(ctx, m); err != nil {
+		return nil, c.abort(ctx, d.fail(m.ID, err))
+	}
+	d.emit(Event{Type: EventMachineStarted, MachineID: m.ID})
+
+	return c, nil
+}
+
+// Health returns the current health check results of the canary.
+func (c *Canary) Health(ctx context.Context) (*fly.HealthCheckStatus, error) {
+	m, err := c.d.client.Get(ctx, c.Machine.ID)
+	if err != nil {
+		return nil, err
+	}
+	c.Machine = m
+	return m.AllHealthChecks(), nil
+}
+
+// Promote destroys the canary and rolls the new configuration out to the
+// original machines.
+func (c *Canary) Promote(ctx context.Context) error {
+	if err := c.destroy(ctx); err != nil {
+		return err
+	}
+	if err := c.d.rolling(ctx); err != nil {
+		return err
+	}
+
+	c.d.emit(Event{Type: EventDeployComplete})
+	return nil
+}
+
+// Abort destroys the canary, leaving the original machines untouched.
+func (c *Canary) Abort(ctx context.Context) error {
+	return c.abort(ctx, nil)
+}
+
+func (c *Canary) abort(ctx context.Context, cause error) error {
+	return errors.Join(cause, c.destroy(ctx))
+}
+
+func (c *Canary) destroy(ctx context.Context) error {
+	if err := c.d.client.Destroy(ctx, fly.RemoveMachineInput{ID: c.Machine.ID, Kill: true}, ""); err != nil {
+		return c.d.fail(c.Machine.ID, err)
+	}
+	c.d.emit(Event{Type: EventMachineDestroyed, MachineID: c.Machine.ID})
+	return nil
+}
+
+func (d *deployer) canary(ctx context.Context) error {
+	c, err := StartCanary(ctx, d.client, d.opts)
+	if err != nil {
+		return err
+	}
+
+	if !d.opts.SkipHealthChecks {
+		if err := d.waitHealthy(ctx, c.Machine); err != nil {
+			return c.abort(ctx, err)
+		}
+	}
+
+	if err := c.destroy(ctx); err != nil {
+		return err
+	}
+	return d.rolling(ctx)
+}
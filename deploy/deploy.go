@@ -0,0 +1,349 @@
+// Package deploy replaces the machines of an app with new configurations
+// using the machines API, with health gating and lease handling.
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/fly-go/flaps"
+)
+
+type Strategy string
+
+const (
+	// StrategyRolling updates machines in place, one batch at a time.
+	StrategyRolling Strategy = "rolling"
+	// StrategyBluegreen launches a replacement for every machine, waits for
+	// all of them to become healthy, then destroys the old machines.
+	StrategyBluegreen Strategy = "bluegreen"
+	// StrategyCanary launches one extra machine with the new configuration
+	// first, and rolls out to the existing machines only once it's healthy.
+	StrategyCanary Strategy = "canary"
+)
+
+const (
+	defaultWaitTimeout = 5 * time.Minute
+	defaultLeaseTTL    = 60
+)
+
+type EventType string
+
+const (
+	EventLeaseAcquired    EventType = "lease_acquired"
+	EventMachineUpdated   EventType = "machine_updated"
+	EventMachineLaunched  EventType = "machine_launched"
+	EventMachineStarted   EventType = "machine_started"
+	EventMachineHealthy   EventType = "machine_healthy"
+	EventMachineFailed    EventType = "machine_failed"
+	EventMachineDestroyed EventType = "machine_destroyed"
+	EventDeployComplete   EventType = "deploy_complete"
+)
+
+// Event reports progress of a deployment. MachineID is empty for events that
+// concern the deployment as a whole.
+type Event struct {
+	Type      EventType
+	MachineID string
+	Err       error
+}
+
+type Options struct {
+	Strategy Strategy
+
+	// Machines are the existing machines to replace.
+	Machines []*fly.Machine
+
+	// Config returns the configuration the replacement for m should run.
+	Config func(m *fly.Machine) *fly.MachineConfig
+
+	// MaxUnavailable is the number of machines a rolling deploy updates at
+	// once. Defaults to 1.
+	MaxUnavailable int
+
+	// WaitTimeout bounds how long to wait for each machine to start and pass
+	// its health checks. Defaults to 5 minutes.
+	WaitTimeout time.Duration
+
+	// LeaseTTL is the lease duration, in seconds, held on machines while they
+	// are being replaced. Leases are refreshed for as long as a replacement
+	// takes. Defaults to 60.
+	LeaseTTL int
+
+	SkipHealthChecks bool
+
+	// OnEvent, when set, is called for every progress event, one at a time.
+	OnEvent func(Event)
+}
+
+// Deploy replaces opts.Machines using the machines client in opts.Strategy.
+func Deploy(ctx context.Context, client *flaps.Client, opts Options) error {
+	d, err := newDeployer(client, opts)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Strategy {
+	case StrategyRolling, "":
+		err = d.rolling(ctx)
+	case StrategyBluegreen:
+		err = d.bluegreen(ctx)
+	case StrategyCanary:
+		err = d.canary(ctx)
+	default:
+		return fmt.Errorf("deploy: unknown strategy '%s'", opts.Strategy)
+	}
+	if err != nil {
+		return err
+	}
+
+	d.emit(Event{Type: EventDeployComplete})
+	return nil
+}
+
+// DeployImage replaces opts.Machines with machines running image, keeping
+// the rest of each machine's configuration. opts.Config is ignored.
+func DeployImage(ctx context.Context, client *flaps.Client, image string, opts Options) error {
+	if image == "" {
+		return errors.New("deploy: an image is required")
+	}
+
+	opts.Config = func(m *fly.Machine) *fly.MachineConfig {
+		config := new(fly.MachineConfig)
+		if m.Config != nil {
+			*config = *m.Config
+		}
+		config.Image = image
+		return config
+	}
+	return Deploy(ctx, client, opts)
+}
+
+type deployer struct {
+	client *flaps.Client
+	opts   Options
+
+	// emitMu serializes OnEvent calls from machines updated concurrently.
+	emitMu sync.Mutex
+}
+
+func newDeployer(client *flaps.Client, opts Options) (*deployer, error) {
+	if opts.Config == nil {
+		return nil, errors.New("deploy: Config is required")
+	}
+	if opts.MaxUnavailable < 1 {
+		opts.MaxUnavailable = 1
+	}
+	if opts.WaitTimeout == 0 {
+		opts.WaitTimeout = defaultWaitTimeout
+	}
+	if opts.LeaseTTL == 0 {
+		opts.LeaseTTL = defaultLeaseTTL
+	}
+	return &deployer{client: client, opts: opts}, nil
+}
+
+func (d *deployer) emit(e Event) {
+	if d.opts.OnEvent != nil {
+		d.emitMu.Lock()
+		defer d.emitMu.Unlock()
+		d.opts.OnEvent(e)
+	}
+}
+
+func (d *deployer) fail(machineID string, err error) error {
+	d.emit(Event{Type: EventMachineFailed, MachineID: machineID, Err: err})
+	return err
+}
+
+func (d *deployer) rolling(ctx context.Context) error {
+	machines := d.opts.Machines
+	for len(machines) > 0 {
+		n := min(d.opts.MaxUnavailable, len(machines))
+		batch := machines[:n]
+		machines = machines[n:]
+
+		errs := make(chan error, len(batch))
+		for _, m := range batch {
+			go func(m *fly.Machine) {
+				errs <- d.updateMachine(ctx, m)
+			}(m)
+		}
+
+		var batchErr error
+		for range batch {
+			batchErr = errors.Join(batchErr, <-errs)
+		}
+		if batchErr != nil {
+			return batchErr
+		}
+	}
+	return nil
+}
+
+func (d *deployer) updateMachine(ctx context.Context, m *fly.Machine) (err error) {
+	lease, err := d.client.AcquireLease(ctx, m.ID, fly.IntPointer(d.opts.LeaseTTL))
+	if err != nil {
+		return d.fail(m.ID, err)
+	}
+	nonce := lease.Data.Nonce
+	d.emit(Event{Type: EventLeaseAcquired, MachineID: m.ID})
+
+	stopRefreshing := d.holdLease(ctx, m.ID, nonce)
+	defer func() {
+		stopRefreshing()
+		if releaseErr := d.client.ReleaseLease(ctx, m.ID, nonce); releaseErr != nil && err == nil {
+			err = d.fail(m.ID, releaseErr)
+		}
+	}()
+
+	updated, err := d.client.Update(ctx, fly.LaunchMachineInput{
+		ID:     m.ID,
+		Region: m.Region,
+		Name:   m.Name,
+		Config: d.opts.Config(m),
+	}, nonce)
+	if err != nil {
+		return d.fail(m.ID, err)
+	}
+	d.emit(Event{Type: EventMachineUpdated, MachineID: m.ID})
+
+	return d.waitHealthy(ctx, updated)
+}
+
+// holdLease refreshes the lease on a machine every half TTL until the
+// returned func is called, so that it outlasts waits longer than the TTL.
+func (d *deployer) holdLease(ctx context.Context, machineID, nonce string) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(time.Duration(d.opts.LeaseTTL) * time.Second / 2)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// A failed refresh leaves the lease to expire, which the
+				// release at the end reports.
+				d.client.RefreshLease(ctx, machineID, fly.IntPointer(d.opts.LeaseTTL), nonce)
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func (d *deployer) bluegreen(ctx context.Context) error {
+	greens := make([]*fly.Machine, 0, len(d.opts.Machines))
+
+	cleanup := func(cause error) error {
+		for _, g := range greens {
+			if err := d.client.Destroy(ctx, fly.RemoveMachineInput{ID: g.ID, Kill: true}, ""); err != nil {
+				cause = errors.Join(cause, err)
+				continue
+			}
+			d.emit(Event{Type: EventMachineDestroyed, MachineID: g.ID})
+		}
+		return cause
+	}
+
+	for _, blue := range d.opts.Machines {
+		green, err := d.client.Launch(ctx, fly.LaunchMachineInput{
+			Region: blue.Region,
+			Config: d.opts.Config(blue),
+		})
+		if err != nil {
+			return cleanup(d.fail(blue.ID, err))
+		}
+		greens = append(greens, green)
+		d.emit(Event{Type: EventMachineLaunched, MachineID: green.ID})
+	}
+
+	for _, green := range greens {
+		if err := d.waitHealthy(ctx, green); err != nil {
+			return cleanup(err)
+		}
+	}
+
+	for _, blue := range d.opts.Machines {
+		if err := d.destroyMachine(ctx, blue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *deployer) destroyMachine(ctx context.Context, m *fly.Machine) error {
+	lease, err := d.client.AcquireLease(ctx, m.ID, fly.IntPointer(d.opts.LeaseTTL))
+	if err != nil {
+		return d.fail(m.ID, err)
+	}
+	d.emit(Event{Type: EventLeaseAcquired, MachineID: m.ID})
+
+	if err := d.client.Destroy(ctx, fly.RemoveMachineInput{ID: m.ID, Kill: true}, lease.Data.Nonce); err != nil {
+		return d.fail(m.ID, err)
+	}
+	d.emit(Event{Type: EventMachineDestroyed, MachineID: m.ID})
+	return nil
+}
+
+// waitStarted waits up to WaitTimeout for m to start. The machines API
+// bounds each wait to a minute, so longer timeouts take several.
+func (d *deployer) waitStarted(ctx context.Context, m *fly.Machine) error {
+	deadline := time.Now().Add(d.opts.WaitTimeout)
+	for {
+		err := d.client.Wait(ctx, m, fly.MachineStateStarted, time.Until(deadline))
+
+		var flapsErr *flaps.FlapsError
+		if err == nil || !errors.As(err, &flapsErr) || flapsErr.ResponseStatusCode != http.StatusRequestTimeout || !time.Now().Before(deadline) {
+			return err
+		}
+	}
+}
+
+func (d *deployer) waitHealthy(ctx context.Context, m *fly.Machine) error {
+	if err := d.waitStarted(ctx, m); err != nil {
+		return d.fail(m.ID, err)
+	}
+	d.emit(Event{Type: EventMachineStarted, MachineID: m.ID})
+
+	if d.opts.SkipHealthChecks {
+		return nil
+	}
+
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = 500 * time.Millisecond
+	b.MaxInterval = 5 * time.Second
+	b.MaxElapsedTime = d.opts.WaitTimeout
+
+	err := backoff.Retry(func() error {
+		current, err := d.client.Get(ctx, m.ID)
+		if err != nil {
+			return backoff.Permanent(err)
+		}
+		if checks := current.AllHealthChecks(); !checks.AllPassing() {
+			return fmt.Errorf("machine %s has %d of %d health checks passing", m.ID, checks.Passing, checks.Total)
+		}
+		return nil
+	}, backoff.WithContext(b, ctx))
+	if err != nil {
+		return d.fail(m.ID, err)
+	}
+
+	d.emit(Event{Type: EventMachineHealthy, MachineID: m.ID})
+	return nil
+}
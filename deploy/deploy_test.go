@@ -0,0 +1,261 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/fly-go/flaps"
+	"github.com/superfly/fly-go/tokens"
+)
+
+// stubFlaps is an in-memory machines API for one app.
+type stubFlaps struct {
+	mu        sync.Mutex
+	machines  map[string]*fly.Machine
+	leases    map[string]time.Time
+	waits     map[string]int
+	launched  int
+	refreshes int
+	actions   []string
+
+	// timeoutWaits is how many waits on each machine time out first, as
+	// the API does after a minute.
+	timeoutWaits int
+	// waitDelay is how long a machine takes to start.
+	waitDelay time.Duration
+}
+
+func newStubFlaps(t *testing.T, ids ...string) (*stubFlaps, *flaps.Client) {
+	s := &stubFlaps{
+		machines: map[string]*fly.Machine{},
+		leases:   map[string]time.Time{},
+		waits:    map[string]int{},
+	}
+	for _, id := range ids {
+		s.machines[id] = &fly.Machine{ID: id, State: "started", Region: "ord", Config: &fly.MachineConfig{Image: "old"}}
+	}
+
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+
+	t.Setenv("FLY_FLAPS_BASE_URL", srv.URL)
+	client, err := flaps.NewWithOptions(context.Background(), flaps.NewClientOpts{AppName: "app", Tokens: tokens.Parse("token")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, client
+}
+
+func (s *stubFlaps) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path, ok := strings.CutPrefix(r.URL.Path, "/v1/apps/app/machines")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	id, sub, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+
+	if sub == "wait" {
+		s.wait(w, id)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := s.machines[id]
+	if id != "" && m == nil {
+		http.Error(w, `{"error":"machine not found"}`, http.StatusNotFound)
+		return
+	}
+
+	switch {
+	case id == "" && r.Method == http.MethodPost:
+		var in fly.LaunchMachineInput
+		json.NewDecoder(r.Body).Decode(&in)
+		s.launched++
+		m = &fly.Machine{ID: "new" + strconv.Itoa(s.launched), State: "started", Region: in.Region, Config: in.Config}
+		s.machines[m.ID] = m
+		s.actions = append(s.actions, "launch "+m.ID)
+		json.NewEncoder(w).Encode(m)
+	case sub == "" && r.Method == http.MethodGet:
+		json.NewEncoder(w).Encode(m)
+	case sub == "" && r.Method == http.MethodPost:
+		var in fly.LaunchMachineInput
+		json.NewDecoder(r.Body).Decode(&in)
+		m.Config = in.Config
+		s.actions = append(s.actions, "update "+id)
+		json.NewEncoder(w).Encode(m)
+	case sub == "" && r.Method == http.MethodDelete:
+		delete(s.machines, id)
+		delete(s.leases, id)
+		s.actions = append(s.actions, "destroy "+id)
+	case sub == "lease":
+		s.lease(w, r, id)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *stubFlaps) lease(w http.ResponseWriter, r *http.Request, id string) {
+	expires, held := s.leases[id]
+	expired := held && time.Now().After(expires)
+	ttl, _ := strconv.Atoi(r.URL.Query().Get("ttl"))
+
+	switch {
+	case r.Method == http.MethodDelete:
+		delete(s.leases, id)
+		if expired {
+			http.Error(w, `{"error":"lease expired"}`, http.StatusConflict)
+		}
+	case r.Header.Get(flaps.NonceHeader) != "":
+		if expired {
+			http.Error(w, `{"error":"lease expired"}`, http.StatusConflict)
+			return
+		}
+		s.refreshes++
+		s.leases[id] = time.Now().Add(time.Duration(ttl) * time.Second)
+		json.NewEncoder(w).Encode(fly.MachineLease{Data: &fly.MachineLeaseData{Nonce: "nonce-" + id}})
+	case held && !expired:
+		http.Error(w, `{"error":"machine is leased"}`, http.StatusConflict)
+	default:
+		s.leases[id] = time.Now().Add(time.Duration(ttl) * time.Second)
+		json.NewEncoder(w).Encode(fly.MachineLease{Data: &fly.MachineLeaseData{Nonce: "nonce-" + id}})
+	}
+}
+
+func (s *stubFlaps) wait(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	s.waits[id]++
+	timeout := s.waits[id] <= s.timeoutWaits
+	delay := s.waitDelay
+	s.mu.Unlock()
+
+	if timeout {
+		http.Error(w, `{"error":"deadline_exceeded"}`, http.StatusRequestTimeout)
+		return
+	}
+	time.Sleep(delay)
+}
+
+func (s *stubFlaps) images() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	images := map[string]string{}
+	for id, m := range s.machines {
+		images[id] = m.Config.Image
+	}
+	return images
+}
+
+func machines(ids ...string) []*fly.Machine {
+	var ms []*fly.Machine
+	for _, id := range ids {
+		ms = append(ms, &fly.Machine{ID: id, Region: "ord", Config: &fly.MachineConfig{Image: "old"}})
+	}
+	return ms
+}
+
+func TestDeploy(t *testing.T) {
+	type testcase struct {
+		name     string
+		strategy Strategy
+		want     map[string]string
+	}
+
+	cases := []testcase{
+		{name: "rolling", strategy: StrategyRolling, want: map[string]string{"m1": "new", "m2": "new", "m3": "new"}},
+		{name: "bluegreen", strategy: StrategyBluegreen, want: map[string]string{"new1": "new", "new2": "new", "new3": "new"}},
+		{name: "canary", strategy: StrategyCanary, want: map[string]string{"m1": "new", "m2": "new", "m3": "new"}},
+	}
+
+	for _, tc := range cases {
+		stub, client := newStubFlaps(t, "m1", "m2", "m3")
+
+		var events []EventType
+		err := DeployImage(context.Background(), client, "new", Options{
+			Strategy:       tc.strategy,
+			Machines:       machines("m1", "m2", "m3"),
+			MaxUnavailable: 2,
+			OnEvent:        func(e Event) { events = append(events, e.Type) },
+		})
+		if err != nil {
+			t.Fatalf("%s, unexpected error: %v", tc.name, err)
+		}
+
+		if got := stub.images(); fmt.Sprint(got) != fmt.Sprint(tc.want) {
+			t.Errorf("%s, got '%v', want '%v'", tc.name, got, tc.want)
+		}
+		if len(stub.leases) != 0 {
+			t.Errorf("%s, got leases '%v', want none", tc.name, stub.leases)
+		}
+		if len(events) == 0 || events[len(events)-1] != EventDeployComplete {
+			t.Errorf("%s, got events '%v', want them to end with '%v'", tc.name, events, EventDeployComplete)
+		}
+	}
+}
+
+func TestDeployRefreshesLease(t *testing.T) {
+	stub, client := newStubFlaps(t, "m1")
+	stub.waitDelay = 1500 * time.Millisecond
+
+	err := DeployImage(context.Background(), client, "new", Options{
+		Machines:         machines("m1"),
+		LeaseTTL:         1,
+		SkipHealthChecks: true,
+	})
+	if err != nil {
+		t.Fatalf("got '%v', want the lease to outlast the wait", err)
+	}
+	if stub.refreshes == 0 {
+		t.Error("lease was never refreshed")
+	}
+}
+
+func TestDeployWaitsPastAPITimeout(t *testing.T) {
+	stub, client := newStubFlaps(t, "m1")
+	stub.timeoutWaits = 2
+
+	err := DeployImage(context.Background(), client, "new", Options{
+		Machines:         machines("m1"),
+		SkipHealthChecks: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := stub.waits["m1"]; got != 3 {
+		t.Errorf("waits, got '%v', want '%v'", got, 3)
+	}
+}
+
+func TestCanaryAbort(t *testing.T) {
+	stub, client := newStubFlaps(t, "m1", "m2")
+
+	c, err := StartCanary(context.Background(), client, Options{
+		Machines: machines("m1", "m2"),
+		Config:   func(*fly.Machine) *fly.MachineConfig { return &fly.MachineConfig{Image: "new"} },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Abort(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := stub.images(), map[string]string{"m1": "old", "m2": "old"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got '%v', want '%v'", got, want)
+	}
+	if want := []string{"launch new1", "destroy new1"}; !slices.Equal(stub.actions, want) {
+		t.Errorf("got '%v', want '%v'", stub.actions, want)
+	}
+}
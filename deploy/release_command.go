@@ -0,0 +1,91 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/fly-go/flaps"
+)
+
+var releaseCommandPollInterval = 2 * time.Second
+
+// StreamReleaseCommand waits for the release command machine of the
+// deployment started at since to appear, calls fn with each of its log
+// entries as they arrive, and returns its final status once it has exited.
+// Returning an error from fn stops streaming and returns that error.
+//
+// The returned status describes a failed command as well as a successful
+// one; use its Succeeded method to tell them apart.
+func StreamReleaseCommand(ctx context.Context, api *fly.Client, client *flaps.Client, appName string, since time.Time, fn func(fly.LogEntry) error) (*flaps.ReleaseCommandStatus, error) {
+	status, err := waitForReleaseCommand(ctx, client, since)
+	if err != nil {
+		return nil, err
+	}
+
+	var token string
+	for {
+		n := 0
+		next, err := api.StreamAppLogs(ctx, appName, token, "", status.MachineID, func(entry fly.LogEntry) error {
+			n++
+			return fn(entry)
+		})
+		if err != nil {
+			return nil, err
+		}
+		// Empty pages come back without a token; reading from the start
+		// again would repeat every line already passed to fn.
+		if next != "" {
+			token = next
+		}
+
+		// Logs trail the machine, so keep reading past the exit until a
+		// page comes back empty.
+		if status.Exited && n == 0 {
+			return status, nil
+		}
+
+		if n == 0 {
+			if err := sleep(ctx, releaseCommandPollInterval); err != nil {
+				return nil, err
+			}
+		}
+
+		if !status.Exited {
+			if status, err = client.ReleaseCommandStatus(ctx); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// waitForReleaseCommand polls until a release command machine created no
+// earlier than since exists.
+func waitForReleaseCommand(ctx context.Context, client *flaps.Client, since time.Time) (*flaps.ReleaseCommandStatus, error) {
+	for {
+		status, err := client.ReleaseCommandStatus(ctx)
+		switch {
+		case err == nil && !status.CreatedAt.Before(since):
+			return status, nil
+		case err != nil && !errors.Is(err, fly.ErrNotFound):
+			return nil, err
+		}
+
+		if err := sleep(ctx, releaseCommandPollInterval); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,112 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/fly-go/flaps"
+	"github.com/superfly/fly-go/tokens"
+)
+
+func TestStreamReleaseCommand(t *testing.T) {
+	defer func(d time.Duration) { releaseCommandPollInterval = d }(releaseCommandPollInterval)
+	releaseCommandPollInterval = time.Millisecond
+
+	var mu sync.Mutex
+	exited := false
+	emptyAfterT1 := 0
+
+	logs := func(w http.ResponseWriter, next string, messages ...string) {
+		var data []string
+		for _, m := range messages {
+			data = append(data, fmt.Sprintf(`{"id":"%s","attributes":{"message":"%s"}}`, m, m))
+		}
+		fmt.Fprintf(w, `{"data":[%s],"meta":{"next_token":"%s"}}`, strings.Join(data, ","), next)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.URL.Path {
+		case "/v1/apps/app/machines":
+			m := &fly.Machine{
+				ID:        "release",
+				State:     "started",
+				CreatedAt: "2024-01-01T00:00:00Z",
+				Config: &fly.MachineConfig{
+					Metadata: map[string]string{fly.MachineConfigMetadataKeyFlyProcessGroup: fly.MachineProcessGroupFlyAppReleaseCommand},
+				},
+			}
+			if exited {
+				m.State = "stopped"
+				m.Events = []*fly.MachineEvent{{
+					Type:      "exit",
+					Timestamp: 1704067260000,
+					Request:   &fly.MachineRequest{ExitEvent: &fly.MachineExitEvent{ExitCode: 0}},
+				}}
+			}
+			json.NewEncoder(w).Encode([]*fly.Machine{m})
+		case "/api/v1/apps/app/logs":
+			if got := r.URL.Query().Get("instance"); got != "release" {
+				t.Errorf("logs instance, got '%v', want '%v'", got, "release")
+			}
+			switch r.URL.Query().Get("next_token") {
+			case "":
+				logs(w, "t1", "1", "2")
+			case "t1":
+				// The first poll comes back empty, without a token.
+				emptyAfterT1++
+				if emptyAfterT1 == 1 {
+					logs(w, "")
+				} else {
+					logs(w, "t2", "3")
+				}
+			case "t2":
+				exited = true
+				logs(w, "")
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	defer fly.SetBaseURL("")
+	fly.SetBaseURL(srv.URL)
+	t.Setenv("FLY_FLAPS_BASE_URL", srv.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	toks := tokens.Parse("token")
+	api := fly.NewClientFromOptions(fly.ClientOptions{BaseURL: srv.URL, Tokens: toks})
+	client, err := flaps.NewWithOptions(ctx, flaps.NewClientOpts{AppName: "app", Tokens: toks})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	status, err := StreamReleaseCommand(ctx, api, client, "app", time.Time{}, func(entry fly.LogEntry) error {
+		got = append(got, entry.Message)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(got, ",") != "1,2,3" {
+		t.Errorf("logs, got '%v', want '%v'", got, []string{"1", "2", "3"})
+	}
+	if !status.Succeeded() {
+		t.Errorf("status, got '%+v', want a successful exit", status)
+	}
+}
@@ -0,0 +1,167 @@
+package fly
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseZonefile parses the BIND zonefile returned by ExportDNSRecords into
+// records. Names are returned as written, relative to the zone's origin, with
+// omitted names filled in from the previous record and omitted TTLs from
+// $TTL.
+func ParseZonefile(zonefile string) ([]DNSRecordAttributes, error) {
+	var (
+		records    []DNSRecordAttributes
+		defaultTTL int
+		lastName   string
+		pending    []string
+		depth      int
+		lineNo     int
+		startLine  int
+		continued  bool
+		leadingGap bool
+	)
+
+	scanner := bufio.NewScanner(strings.NewReader(zonefile))
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+
+		fields, open, err := zonefileFields(line)
+		if err != nil {
+			return nil, fmt.Errorf("zonefile line %d: %w", lineNo, err)
+		}
+
+		if !continued {
+			if len(fields) == 0 {
+				continue
+			}
+			startLine = lineNo
+			leadingGap = line[0] == ' ' || line[0] == '\t'
+		}
+		pending = append(pending, fields...)
+		depth += open
+		if depth > 0 {
+			continued = true
+			continue
+		}
+		if depth < 0 {
+			return nil, fmt.Errorf("zonefile line %d: unbalanced parentheses", lineNo)
+		}
+
+		fields, pending, continued = pending, nil, false
+
+		switch strings.ToUpper(fields[0]) {
+		case "$TTL":
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("zonefile line %d: $TTL without a value", startLine)
+			}
+			defaultTTL, err = strconv.Atoi(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("zonefile line %d: invalid $TTL: %w", startLine, err)
+			}
+			continue
+		case "$ORIGIN", "$INCLUDE", "$GENERATE":
+			continue
+		}
+
+		rec := DNSRecordAttributes{TTL: defaultTTL}
+		if leadingGap {
+			rec.Name = lastName
+		} else {
+			rec.Name, fields = fields[0], fields[1:]
+		}
+
+		// TTL and class may appear in either order before the type.
+		for len(fields) > 0 {
+			if ttl, err := strconv.Atoi(fields[0]); err == nil {
+				rec.TTL = ttl
+			} else if !isZonefileClass(fields[0]) {
+				break
+			}
+			fields = fields[1:]
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("zonefile line %d: record without type or data", startLine)
+		}
+
+		rec.Type = strings.ToUpper(fields[0])
+		rec.Rdata = strings.Join(fields[1:], " ")
+		lastName = rec.Name
+
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if continued {
+		return nil, fmt.Errorf("zonefile line %d: unterminated parentheses", startLine)
+	}
+
+	return records, nil
+}
+
+// zonefileFields splits a zonefile line into fields, dropping comments and
+// parentheses. Quoted strings are kept whole, quotes included. It also
+// returns the net number of parentheses opened on the line.
+func zonefileFields(line string) ([]string, int, error) {
+	var (
+		fields []string
+		field  strings.Builder
+		quoted bool
+		open   int
+	)
+
+	flush := func() {
+		if field.Len() > 0 {
+			fields = append(fields, field.String())
+			field.Reset()
+		}
+	}
+
+	for i := 0; i < len(line); i++ {
+		ch := line[i]
+		switch {
+		case quoted:
+			field.WriteByte(ch)
+			if ch == '\\' && i+1 < len(line) {
+				i++
+				field.WriteByte(line[i])
+			} else if ch == '"' {
+				quoted = false
+			}
+		case ch == '"':
+			quoted = true
+			field.WriteByte(ch)
+		case ch == ';':
+			flush()
+			return fields, open, nil
+		case ch == '(':
+			flush()
+			open++
+		case ch == ')':
+			flush()
+			open--
+		case ch == ' ' || ch == '\t':
+			flush()
+		default:
+			field.WriteByte(ch)
+		}
+	}
+	if quoted {
+		return nil, 0, fmt.Errorf("unterminated quoted string")
+	}
+	flush()
+
+	return fields, open, nil
+}
+
+func isZonefileClass(s string) bool {
+	switch strings.ToUpper(s) {
+	case "IN", "CH", "HS", "CS":
+		return true
+	}
+	return false
+}
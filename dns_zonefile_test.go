@@ -0,0 +1,41 @@
+package fly
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseZonefile(t *testing.T) {
+	zonefile := `$ORIGIN example.com.
+$TTL 3600
+@	IN	SOA	ns1.example.com. admin.example.com. (
+		2024010101 ; serial
+		7200 3600 1209600 300 )
+@	300	IN	A	1.2.3.4
+	IN	AAAA	2a09::1
+www	CNAME	example.com.
+txt	IN 60	TXT	"v=spf1 include:_spf.example.com; ~all"
+`
+
+	want := []DNSRecordAttributes{
+		{Name: "@", Type: "SOA", TTL: 3600, Rdata: "ns1.example.com. admin.example.com. 2024010101 7200 3600 1209600 300"},
+		{Name: "@", Type: "A", TTL: 300, Rdata: "1.2.3.4"},
+		{Name: "@", Type: "AAAA", TTL: 3600, Rdata: "2a09::1"},
+		{Name: "www", Type: "CNAME", TTL: 3600, Rdata: "example.com."},
+		{Name: "txt", Type: "TXT", TTL: 60, Rdata: `"v=spf1 include:_spf.example.com; ~all"`},
+	}
+
+	got, err := ParseZonefile(zonefile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got '%v', want '%v'", got, want)
+	}
+
+	for _, bad := range []string{"www CNAME", "@ SOA ( 1 2", `txt TXT "unterminated`} {
+		if _, err := ParseZonefile(bad); err == nil {
+			t.Errorf("%s, got no error, want an error", bad)
+		}
+	}
+}
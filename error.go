@@ -1,46 +1,243 @@
 package fly
 
-import "net/http"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
 
+// ApiError is returned by REST calls that fail. Details and Body hold what
+// the server said about the failure, and RequestID identifies the request
+// for support.
 type ApiError struct {
 	WrappedError error
 	Message      string
 	Status       int
+	RequestID    string
+	Details      []ApiErrorDetail
+	Body         []byte
+	// RateLimit is set when the response reported rate limit state, such as
+	// for ErrRateLimited.
+	RateLimit *RateLimitInfo
+}
+
+// ApiErrorDetail is one entry of a JSON:API error response.
+type ApiErrorDetail struct {
+	Code   string `json:"code"`
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+}
+
+func (d ApiErrorDetail) message() string {
+	if d.Detail != "" {
+		return d.Detail
+	}
+	return d.Title
 }
 
 func (e *ApiError) Error() string { return e.Message }
 
+func (e *ApiError) Unwrap() error { return e.WrappedError }
+
+const maxErrorBodyBytes = 64 * 1024
+
+// ErrorFromResp reads the body of a failed response into an ApiError.
 func ErrorFromResp(resp *http.Response) *ApiError {
-	return &ApiError{
-		Message: resp.Status,
-		Status:  resp.StatusCode,
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+
+	e := newApiError(resp.StatusCode, body)
+	e.RequestID = resp.Header.Get("Fly-Request-Id")
+	if info, ok := rateLimitFromHeader(resp.Header, time.Now()); ok {
+		e.RateLimit = &info
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		e.WrappedError = ErrRateLimited
+	}
+	e.Message = resp.Status
+	if msgs := e.messages(); len(msgs) > 0 {
+		e.Message = fmt.Sprintf("%s: %s", e.Message, strings.Join(msgs, ", "))
+	}
+	return e
+}
+
+func newApiError(status int, body []byte) *ApiError {
+	var parsed struct {
+		Errors  []ApiErrorDetail `json:"errors"`
+		Error   string           `json:"error"`
+		Message string           `json:"message"`
+	}
+	_ = json.Unmarshal(body, &parsed)
+
+	details := parsed.Errors
+	switch {
+	case parsed.Error != "":
+		details = append(details, ApiErrorDetail{Detail: parsed.Error})
+	case parsed.Message != "":
+		details = append(details, ApiErrorDetail{Detail: parsed.Message})
+	}
+
+	return &ApiError{Status: status, Details: details, Body: body}
+}
+
+func (e *ApiError) messages() []string {
+	var msgs []string
+	for _, d := range e.Details {
+		if msg := d.message(); msg != "" {
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs
+}
+
+// accessTokenError turns a failed sessions API response into an ApiError
+// wrapping ErrTwoFactorRequired, ErrInvalidCredentials, ErrRateLimited or
+// ErrUnknown, with the message the server sent, if any.
+func accessTokenError(status int, body []byte) *ApiError {
+	e := newApiError(status, body)
+
+	twoFactor := false
+	for _, d := range e.Details {
+		text := strings.ToLower(d.Code + " " + d.message())
+		if strings.Contains(text, "otp") || strings.Contains(text, "two factor") || strings.Contains(text, "two-factor") || strings.Contains(text, "2fa") {
+			twoFactor = true
+		}
+	}
+
+	e.WrappedError = ErrInvalidCredentials
+	switch {
+	case status == http.StatusTooManyRequests:
+		e.WrappedError = ErrRateLimited
+	case status >= http.StatusInternalServerError:
+		e.WrappedError = ErrUnknown
+	case twoFactor:
+		e.WrappedError = ErrTwoFactorRequired
 	}
+
+	e.Message = e.WrappedError.Error()
+	if msgs := e.messages(); len(msgs) > 0 {
+		e.Message = fmt.Sprintf("%s: %s", e.Message, strings.Join(msgs, ", "))
+	}
+	return e
 }
 
 func IsNotAuthenticatedError(err error) bool {
-	if apiErr, ok := err.(*ApiError); ok {
+	var apiErr *ApiError
+	if errors.As(err, &apiErr) {
 		return apiErr.Status == 401
 	}
 	return false
 }
 
 func IsNotFoundError(err error) bool {
-	if apiErr, ok := err.(*ApiError); ok {
+	var apiErr *ApiError
+	if errors.As(err, &apiErr) {
 		return apiErr.Status == 404
 	}
 	return false
 }
 
 func IsServerError(err error) bool {
-	if apiErr, ok := err.(*ApiError); ok {
+	var apiErr *ApiError
+	if errors.As(err, &apiErr) {
 		return apiErr.Status >= 500
 	}
 	return false
 }
 
 func IsClientError(err error) bool {
-	if apiErr, ok := err.(*ApiError); ok {
+	var apiErr *ApiError
+	if errors.As(err, &apiErr) {
 		return apiErr.Status >= 400 && apiErr.Status < 500
 	}
 	return false
 }
+
+// OperationError is returned by GraphQL calls that fail. It records which
+// operation failed and the identifiers, such as the app name or org slug, it
+// was called with. Use errors.As to get at the underlying error.
+type OperationError struct {
+	Operation   string
+	Identifiers map[string]string
+	Err         error
+}
+
+func (e *OperationError) Error() string {
+	if len(e.Identifiers) == 0 {
+		return fmt.Sprintf("%s: %v", e.Operation, e.Err)
+	}
+
+	ids := make([]string, 0, len(e.Identifiers))
+	for k, v := range e.Identifiers {
+		ids = append(ids, k+"="+v)
+	}
+	sort.Strings(ids)
+
+	return fmt.Sprintf("%s (%s): %v", e.Operation, strings.Join(ids, " "), e.Err)
+}
+
+func (e *OperationError) Unwrap() error { return e.Err }
+
+// identifierVars are the request variables worth reporting in an
+// OperationError.
+var identifierVars = map[string]bool{
+	"appName":         true,
+	"appId":           true,
+	"postgresAppName": true,
+	"slug":            true,
+	"orgSlug":         true,
+	"orgId":           true,
+	"org":             true,
+	"organizationId":  true,
+	"domainName":      true,
+	"hostname":        true,
+	"machineId":       true,
+	"volumeId":        true,
+	"name":            true,
+}
+
+func newOperationError(operation string, vars map[string]interface{}, err error) *OperationError {
+	ids := map[string]string{}
+
+	// Mutations take their arguments as a single input object, so look one
+	// level into it. Top-level variables win over fields of the same name.
+	if input, ok := vars["input"]; ok && input != nil {
+		if fields, ok := inputFields(input); ok {
+			collectIdentifiers(ids, fields)
+		}
+	}
+	collectIdentifiers(ids, vars)
+	return &OperationError{Operation: operation, Identifiers: ids, Err: err}
+}
+
+func collectIdentifiers(ids map[string]string, vars map[string]interface{}) {
+	for k, v := range vars {
+		if !identifierVars[k] {
+			continue
+		}
+		if s, ok := v.(string); ok && s != "" {
+			ids[k] = s
+		}
+	}
+}
+
+// inputFields returns the fields of an input variable as they are sent to
+// the API, which for structs means after applying their json tags.
+func inputFields(input interface{}) (map[string]interface{}, bool) {
+	if m, ok := input.(map[string]interface{}); ok {
+		return m, true
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, false
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, false
+	}
+	return fields, true
+}
@@ -0,0 +1,117 @@
+package fly
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestOperationError(t *testing.T) {
+	inner := &ApiError{Message: "not found", Status: 404}
+	err := newOperationError("get_app", map[string]interface{}{
+		"appName": "web",
+		"slug":    "personal",
+		"input":   map[string]string{"appId": "web"},
+		"after":   "cursor",
+	}, inner)
+
+	if got, want := err.Error(), "get_app (appId=web appName=web slug=personal): not found"; got != want {
+		t.Errorf("got '%v', want '%v'", got, want)
+	}
+	if !errors.Is(err, inner) || !IsNotFoundError(err) {
+		t.Error("want wrapped error to be reachable")
+	}
+
+	type input struct {
+		AppID          string `json:"appId"`
+		OrganizationID string `json:"organizationId"`
+		Name           string `json:"name"`
+		Secret         string `json:"secret"`
+	}
+	err = newOperationError("create_app", map[string]interface{}{
+		"name":  "top",
+		"input": input{AppID: "web", OrganizationID: "org1", Name: "nested", Secret: "hunter2"},
+	}, inner)
+	if got, want := err.Error(), "create_app (appId=web name=top organizationId=org1): not found"; got != want {
+		t.Errorf("got '%v', want '%v'", got, want)
+	}
+}
+
+func TestAccessTokenError(t *testing.T) {
+	type testcase struct {
+		name    string
+		status  int
+		body    string
+		want    error
+		message string
+	}
+
+	cases := []testcase{
+		{
+			name:    "bad password",
+			status:  401,
+			body:    `{"errors":[{"status":"401","title":"Unauthorized","detail":"Incorrect email or password"}]}`,
+			want:    ErrInvalidCredentials,
+			message: "Incorrect email and password combination: Incorrect email or password",
+		},
+		{
+			name:    "otp required",
+			status:  401,
+			body:    `{"errors":[{"code":"otp_required","detail":"Enter your one-time password"}]}`,
+			want:    ErrTwoFactorRequired,
+			message: "A one-time password is required: Enter your one-time password",
+		},
+		{
+			name:    "rate limited",
+			status:  429,
+			body:    `Too Many Requests`,
+			want:    ErrRateLimited,
+			message: "Too many requests, please try again later",
+		},
+		{
+			name:    "server error",
+			status:  502,
+			body:    ``,
+			want:    ErrUnknown,
+			message: "An unknown server error occurred, please try again",
+		},
+	}
+
+	for _, tc := range cases {
+		err := accessTokenError(tc.status, []byte(tc.body))
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s, got '%v', want '%v'", tc.name, err.WrappedError, tc.want)
+		}
+		if err.Error() != tc.message {
+			t.Errorf("%s, got '%v', want '%v'", tc.name, err.Error(), tc.message)
+		}
+		if err.Status != tc.status {
+			t.Errorf("%s, got '%v', want '%v'", tc.name, err.Status, tc.status)
+		}
+	}
+}
+
+func TestErrorFromResp(t *testing.T) {
+	resp := &http.Response{
+		Status:     "422 Unprocessable Entity",
+		StatusCode: 422,
+		Header:     http.Header{"Fly-Request-Id": {"01H-req"}},
+		Body:       io.NopCloser(strings.NewReader(`{"errors":[{"code":"invalid","detail":"region is not valid"}]}`)),
+	}
+
+	err := ErrorFromResp(resp)
+	if got, want := err.Error(), "422 Unprocessable Entity: region is not valid"; got != want {
+		t.Errorf("got '%v', want '%v'", got, want)
+	}
+	if err.RequestID != "01H-req" {
+		t.Errorf("got '%v', want '%v'", err.RequestID, "01H-req")
+	}
+	if len(err.Details) != 1 || err.Details[0].Code != "invalid" {
+		t.Errorf("got '%+v', want one 'invalid' detail", err.Details)
+	}
+	if !IsClientError(err) {
+		t.Error("want a client error")
+	}
+}
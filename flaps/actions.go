@@ -18,6 +18,7 @@ const (
 	machineList
 	machineDestroy
 	machineKill
+	machineSignal
 	machineFindLease
 	machineAcquireLease
 	machineRefreshLease
@@ -0,0 +1,72 @@
+package flaps
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	fly "github.com/superfly/fly-go"
+)
+
+const (
+	ScaleUp   = "up"
+	ScaleDown = "down"
+)
+
+// AutoscalingEvent is a machine started or stopped by the proxy in response
+// to traffic, as configured by a service's auto_start_machines and
+// auto_stop_machines.
+type AutoscalingEvent struct {
+	MachineID    string
+	Region       string
+	ProcessGroup string
+	// Direction is ScaleUp or ScaleDown.
+	Direction string
+	// Reason is the machine event type, such as "start", "stop" or "suspend".
+	Reason string
+	Time   time.Time
+}
+
+// AutoscalingEvents returns the autoscaling decisions recorded in the recent
+// events of the app's machines since the given time, oldest first. Machines
+// only keep their last few events, so busy apps may have a shorter history.
+func (f *Client) AutoscalingEvents(ctx context.Context, since time.Time) ([]AutoscalingEvent, error) {
+	machines, err := f.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	return autoscalingEvents(machines, since), nil
+}
+
+func autoscalingEvents(machines []*fly.Machine, since time.Time) []AutoscalingEvent {
+	events := []AutoscalingEvent{}
+	for _, m := range machines {
+		for _, e := range m.Events {
+			if e.Source != "fly-proxy" || !e.Time().After(since) {
+				continue
+			}
+
+			var direction string
+			switch e.Type {
+			case "start":
+				direction = ScaleUp
+			case "stop", "suspend":
+				direction = ScaleDown
+			default:
+				continue
+			}
+
+			events = append(events, AutoscalingEvent{
+				MachineID:    m.ID,
+				Region:       m.Region,
+				ProcessGroup: m.ProcessGroup(),
+				Direction:    direction,
+				Reason:       e.Type,
+				Time:         e.Time(),
+			})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+	return events
+}
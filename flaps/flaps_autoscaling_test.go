@@ -0,0 +1,43 @@
+package flaps
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	fly "github.com/superfly/fly-go"
+)
+
+func TestAutoscalingEvents(t *testing.T) {
+	since := time.UnixMilli(1000)
+	machines := []*fly.Machine{
+		{
+			ID:     "m1",
+			Region: "ord",
+			Events: []*fly.MachineEvent{
+				{Type: "stop", Source: "fly-proxy", Timestamp: 3000},
+				{Type: "start", Source: "fly-proxy", Timestamp: 2000},
+				{Type: "start", Source: "fly-proxy", Timestamp: 500},
+			},
+		},
+		{
+			ID:     "m2",
+			Region: "ams",
+			Events: []*fly.MachineEvent{
+				{Type: "start", Source: "user", Timestamp: 2500},
+				{Type: "exit", Source: "flyd", Timestamp: 2400},
+				{Type: "suspend", Source: "fly-proxy", Timestamp: 1500},
+			},
+		},
+	}
+
+	want := []AutoscalingEvent{
+		{MachineID: "m2", Region: "ams", Direction: ScaleDown, Reason: "suspend", Time: time.UnixMilli(1500)},
+		{MachineID: "m1", Region: "ord", Direction: ScaleUp, Reason: "start", Time: time.UnixMilli(2000)},
+		{MachineID: "m1", Region: "ord", Direction: ScaleDown, Reason: "stop", Time: time.UnixMilli(3000)},
+	}
+
+	if got := autoscalingEvents(machines, since); !reflect.DeepEqual(got, want) {
+		t.Errorf("got '%v', want '%v'", got, want)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"slices"
 	"time"
 
@@ -181,6 +182,31 @@ func (f *Client) GetMany(ctx context.Context, machineIDs []string) ([]*fly.Machi
 	return machines, nil
 }
 
+// GetChecks returns the current health check results for a single machine.
+func (f *Client) GetChecks(ctx context.Context, machineID string) ([]*fly.MachineCheckStatus, error) {
+	m, err := f.Get(ctx, machineID)
+	if err != nil {
+		return nil, err
+	}
+	return m.Checks, nil
+}
+
+// ListChecks returns the health check results of every machine in the app,
+// keyed by machine ID. It makes a single list call instead of fetching each
+// machine individually.
+func (f *Client) ListChecks(ctx context.Context) (map[string][]*fly.MachineCheckStatus, error) {
+	machines, err := f.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string][]*fly.MachineCheckStatus, len(machines))
+	for _, m := range machines {
+		out[m.ID] = m.Checks
+	}
+	return out, nil
+}
+
 func (f *Client) List(ctx context.Context, state string) ([]*fly.Machine, error) {
 	getEndpoint := ""
 
@@ -198,6 +224,59 @@ func (f *Client) List(ctx context.Context, state string) ([]*fly.Machine, error)
 	return out, nil
 }
 
+// ListOptions filters the machines returned by ListWithOptions.
+type ListOptions struct {
+	IncludeDeleted bool   `url:"include_deleted,omitempty"`
+	Region         string `url:"region,omitempty"`
+	State          string `url:"state,omitempty"`
+	// Summary omits the full machine config from the response, which is much
+	// faster for apps with a large number of machines.
+	Summary bool `url:"summary,omitempty"`
+
+	// Metadata restricts the results to machines having all of these
+	// metadata key/value pairs.
+	Metadata map[string]string `url:"-"`
+	// ProcessGroup is a shorthand for filtering on the fly_process_group
+	// metadata key.
+	ProcessGroup string `url:"-"`
+}
+
+func (o ListOptions) values() (url.Values, error) {
+	vals, err := query.Values(o)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range o.Metadata {
+		vals.Set("metadata."+k, v)
+	}
+	if o.ProcessGroup != "" {
+		vals.Set("metadata."+fly.MachineConfigMetadataKeyFlyProcessGroup, o.ProcessGroup)
+	}
+	return vals, nil
+}
+
+// ListWithOptions returns the machines matching opts.
+func (f *Client) ListWithOptions(ctx context.Context, opts ListOptions) ([]*fly.Machine, error) {
+	qsVals, err := opts.values()
+	if err != nil {
+		return nil, fmt.Errorf("error making query string for list request: %w", err)
+	}
+
+	getEndpoint := ""
+	if len(qsVals) > 0 {
+		getEndpoint = fmt.Sprintf("?%s", qsVals.Encode())
+	}
+
+	out := make([]*fly.Machine, 0)
+	ctx = contextWithAction(ctx, machineList)
+
+	err = f.sendRequestMachines(ctx, http.MethodGet, getEndpoint, nil, &out, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list VMs: %w", err)
+	}
+	return out, nil
+}
+
 // ListActive returns only non-destroyed that aren't in a reserved process group.
 func (f *Client) ListActive(ctx context.Context) ([]*fly.Machine, error) {
 	getEndpoint := ""
@@ -284,6 +363,76 @@ func (f *Client) Kill(ctx context.Context, machineID string) (err error) {
 	return
 }
 
+// Signal sends a signal, such as SIGTERM or SIGUSR1, to the main process of
+// a machine.
+func (f *Client) Signal(ctx context.Context, in fly.SignalMachineInput, nonce string) (err error) {
+	headers := make(map[string][]string)
+	if nonce != "" {
+		headers[NonceHeader] = []string{nonce}
+	}
+
+	ctx = contextWithAction(ctx, machineSignal)
+	ctx = contextWithMachineID(ctx, in.ID)
+
+	err = f.sendRequestMachines(ctx, http.MethodPost, fmt.Sprintf("/%s/signal", in.ID), in, nil, headers)
+	if err != nil {
+		return fmt.Errorf("failed to signal VM %s: %w", in.ID, err)
+	}
+	return
+}
+
+// StartMachine starts a machine and waits for it to reach the started
+// state, returning the machine as it is afterwards.
+func (f *Client) StartMachine(ctx context.Context, machineID string, nonce string) (*fly.Machine, error) {
+	machine, err := f.Get(ctx, machineID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Start(ctx, machineID, nonce); err != nil {
+		return nil, err
+	}
+	return f.waitAndGet(ctx, machine, fly.MachineStateStarted)
+}
+
+// StopMachine stops a machine with the signal and timeout set in the input
+// and waits for it to reach the stopped state, returning the machine as it is
+// afterwards. Leave Signal empty for a graceful stop using the machine's
+// configured stop signal, or use SIGKILL to kill it outright.
+func (f *Client) StopMachine(ctx context.Context, in fly.StopMachineInput, nonce string) (*fly.Machine, error) {
+	machine, err := f.Get(ctx, in.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Stop(ctx, in, nonce); err != nil {
+		return nil, err
+	}
+	return f.waitAndGet(ctx, machine, fly.MachineStateStopped)
+}
+
+// RestartMachine restarts a machine and waits for it to be started again,
+// returning the machine as it is afterwards.
+func (f *Client) RestartMachine(ctx context.Context, in fly.RestartMachineInput, nonce string) (*fly.Machine, error) {
+	machine, err := f.Get(ctx, in.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Restart(ctx, in, nonce); err != nil {
+		return nil, err
+	}
+	return f.waitAndGet(ctx, machine, fly.MachineStateStarted)
+}
+
+// waitAndGet waits for machine, as it was before the action that's moving
+// it to state, and returns it as it is afterwards. Waiting for some states
+// requires the machine's instance ID, which is why the caller passes the
+// machine rather than its ID.
+func (f *Client) waitAndGet(ctx context.Context, machine *fly.Machine, state string) (*fly.Machine, error) {
+	if err := f.Wait(ctx, machine, state, proxyTimeoutThreshold); err != nil {
+		return nil, err
+	}
+	return f.Get(ctx, machine.ID)
+}
+
 func (f *Client) FindLease(ctx context.Context, machineID string) (*fly.MachineLease, error) {
 	endpoint := fmt.Sprintf("/%s/lease", machineID)
 
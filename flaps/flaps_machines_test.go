@@ -0,0 +1,72 @@
+package flaps
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/fly-go/tokens"
+)
+
+func TestListOptionsValues(t *testing.T) {
+	type testcase struct {
+		name string
+		in   ListOptions
+		want string
+	}
+
+	cases := []testcase{
+		{name: "empty", in: ListOptions{}, want: ""},
+		{name: "state and region", in: ListOptions{State: "started", Region: "ord"}, want: "region=ord&state=started"},
+		{name: "summary", in: ListOptions{Summary: true}, want: "summary=true"},
+		{
+			name: "metadata and process group",
+			in:   ListOptions{Metadata: map[string]string{"foo": "bar"}, ProcessGroup: "web"},
+			want: "metadata.fly_process_group=web&metadata.foo=bar",
+		},
+	}
+	for _, tc := range cases {
+		vals, err := tc.in.values()
+		if err != nil {
+			t.Fatalf("%s, unexpected error: %v", tc.name, err)
+		}
+		if got := vals.Encode(); got != tc.want {
+			t.Errorf("%s, got '%v', want '%v'", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestStopMachineWaitsForInstance(t *testing.T) {
+	var waitedFor string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/apps/app/machines/m1":
+			json.NewEncoder(w).Encode(fly.Machine{ID: "m1", InstanceID: "inst1", State: "started"})
+		case "/v1/apps/app/machines/m1/stop":
+		case "/v1/apps/app/machines/m1/wait":
+			waitedFor = r.URL.Query().Get("instance_id")
+			if waitedFor == "" {
+				http.Error(w, `{"error":"instance_id is required"}`, http.StatusBadRequest)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	t.Setenv("FLY_FLAPS_BASE_URL", srv.URL)
+	client, err := NewWithOptions(context.Background(), NewClientOpts{AppName: "app", Tokens: tokens.Parse("token")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.StopMachine(context.Background(), fly.StopMachineInput{ID: "m1"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if waitedFor != "inst1" {
+		t.Errorf("got '%v', want '%v'", waitedFor, "inst1")
+	}
+}
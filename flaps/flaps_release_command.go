@@ -0,0 +1,92 @@
+package flaps
+
+import (
+	"context"
+	"time"
+
+	fly "github.com/superfly/fly-go"
+)
+
+// ReleaseCommandStatus is the state of the most recent release command
+// machine of an app. Its logs can be fetched by passing MachineID as the
+// instance to fly.Client.GetAppLogs.
+type ReleaseCommandStatus struct {
+	MachineID string
+	ImageRef  string
+	State     string
+	CreatedAt time.Time
+
+	// Exited is set once the command has finished, successfully or not.
+	Exited    bool
+	ExitCode  int
+	OOMKilled bool
+	ExitedAt  time.Time
+}
+
+// Succeeded reports whether the release command exited with status 0.
+func (s *ReleaseCommandStatus) Succeeded() bool {
+	return s.Exited && s.ExitCode == 0 && !s.OOMKilled
+}
+
+// ReleaseCommandStatus returns the status of the app's most recent release
+// command, including one whose machine has already been destroyed. It
+// returns fly.ErrNotFound if the app has never run a release command.
+func (f *Client) ReleaseCommandStatus(ctx context.Context) (*ReleaseCommandStatus, error) {
+	machines, err := f.ListWithOptions(ctx, ListOptions{IncludeDeleted: true})
+	if err != nil {
+		return nil, err
+	}
+
+	status := releaseCommandStatus(machines)
+	if status == nil {
+		return nil, fly.ErrNotFound
+	}
+	return status, nil
+}
+
+func releaseCommandStatus(machines []*fly.Machine) *ReleaseCommandStatus {
+	var latest *fly.Machine
+	for _, m := range machines {
+		if m.Config == nil || !m.IsReleaseCommandMachine() {
+			continue
+		}
+		if latest == nil || m.CreatedTime().After(latest.CreatedTime()) {
+			latest = m
+		}
+	}
+	if latest == nil {
+		return nil
+	}
+
+	status := &ReleaseCommandStatus{
+		MachineID: latest.ID,
+		ImageRef:  latest.FullImageRef(),
+		State:     latest.State,
+		CreatedAt: latest.CreatedTime(),
+		ExitCode:  -1,
+	}
+
+	// Events are newest first.
+	for _, e := range latest.Events {
+		if e.Type != "exit" || e.Request == nil {
+			continue
+		}
+		code, err := e.Request.GetExitCode()
+		if err != nil {
+			continue
+		}
+
+		status.Exited = true
+		status.ExitCode = code
+		status.ExitedAt = e.Time()
+		if exit := e.Request.ExitEvent; exit != nil {
+			status.OOMKilled = exit.OOMKilled
+		}
+		if e.Request.MonitorEvent != nil && e.Request.MonitorEvent.ExitEvent != nil {
+			status.OOMKilled = e.Request.MonitorEvent.ExitEvent.OOMKilled
+		}
+		break
+	}
+
+	return status
+}
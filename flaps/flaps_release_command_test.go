@@ -0,0 +1,49 @@
+package flaps
+
+import (
+	"testing"
+
+	fly "github.com/superfly/fly-go"
+)
+
+func TestReleaseCommandStatus(t *testing.T) {
+	releaseCommand := func(id, createdAt string, events ...*fly.MachineEvent) *fly.Machine {
+		return &fly.Machine{
+			ID:        id,
+			State:     "destroyed",
+			CreatedAt: createdAt,
+			Config: &fly.MachineConfig{
+				Metadata: map[string]string{fly.MachineConfigMetadataKeyFlyProcessGroup: fly.MachineProcessGroupFlyAppReleaseCommand},
+			},
+			Events: events,
+		}
+	}
+	exit := func(code int) *fly.MachineEvent {
+		return &fly.MachineEvent{
+			Type:      "exit",
+			Timestamp: 1700000000000,
+			Request:   &fly.MachineRequest{ExitEvent: &fly.MachineExitEvent{ExitCode: code}},
+		}
+	}
+
+	machines := []*fly.Machine{
+		{ID: "app", CreatedAt: "2024-01-03T00:00:00Z", Config: &fly.MachineConfig{}},
+		releaseCommand("old", "2024-01-01T00:00:00Z", exit(0)),
+		releaseCommand("new", "2024-01-02T00:00:00Z", exit(1), &fly.MachineEvent{Type: "start"}),
+	}
+
+	status := releaseCommandStatus(machines)
+	if status == nil {
+		t.Fatal("want a release command status")
+	}
+	if status.MachineID != "new" {
+		t.Errorf("got '%v', want '%v'", status.MachineID, "new")
+	}
+	if !status.Exited || status.ExitCode != 1 || status.Succeeded() {
+		t.Errorf("got '%+v', want a failed exit with code 1", status)
+	}
+
+	if status := releaseCommandStatus(machines[:1]); status != nil {
+		t.Errorf("got '%+v', want nil", status)
+	}
+}
@@ -81,7 +81,13 @@ func (f *Client) GetVolume(ctx context.Context, volumeId string) (*fly.Volume, e
 	return out, nil
 }
 
+// Deprecated: use ListVolumeSnapshots instead.
 func (f *Client) GetVolumeSnapshots(ctx context.Context, volumeId string) ([]fly.VolumeSnapshot, error) {
+	return f.ListVolumeSnapshots(ctx, volumeId)
+}
+
+// ListVolumeSnapshots returns the snapshots taken of a volume.
+func (f *Client) ListVolumeSnapshots(ctx context.Context, volumeId string) ([]fly.VolumeSnapshot, error) {
 	getVolumeSnapshotsEndpoint := fmt.Sprintf("/%s/snapshots", volumeId)
 
 	out := make([]fly.VolumeSnapshot, 0)
@@ -107,6 +113,33 @@ func (f *Client) CreateVolumeSnapshot(ctx context.Context, volumeId string) erro
 	return nil
 }
 
+// RestoreVolumeSnapshot creates a new volume from a snapshot of volumeId. The
+// new volume gets the name, region and size of the source volume; req can be
+// used to override those and any other creation parameters.
+func (f *Client) RestoreVolumeSnapshot(ctx context.Context, volumeId, snapshotId string, req fly.CreateVolumeRequest) (*fly.Volume, error) {
+	source, err := f.GetVolume(ctx, volumeId)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name == "" {
+		req.Name = source.Name
+	}
+	if req.Region == "" {
+		req.Region = source.Region
+	}
+	if req.SizeGb == nil {
+		req.SizeGb = &source.SizeGb
+	}
+	req.SnapshotID = &snapshotId
+
+	vol, err := f.CreateVolume(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore snapshot %s of volume %s: %w", snapshotId, volumeId, err)
+	}
+	return vol, nil
+}
+
 type ExtendVolumeRequest struct {
 	SizeGB int `json:"size_gb"`
 }
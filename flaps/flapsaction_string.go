@@ -20,34 +20,36 @@ func _() {
 	_ = x[machineList-9]
 	_ = x[machineDestroy-10]
 	_ = x[machineKill-11]
-	_ = x[machineFindLease-12]
-	_ = x[machineAcquireLease-13]
-	_ = x[machineRefreshLease-14]
-	_ = x[machineReleaseLease-15]
-	_ = x[machineExec-16]
-	_ = x[machinePs-17]
-	_ = x[machineCordon-18]
-	_ = x[machineUncordon-19]
-	_ = x[volumeList-20]
-	_ = x[volumeCreate-21]
-	_ = x[volumetUpdate-22]
-	_ = x[volumeGet-23]
-	_ = x[volumeSnapshotCreate-24]
-	_ = x[volumeSnapshotList-25]
-	_ = x[volumeExtend-26]
-	_ = x[volumeDelete-27]
-	_ = x[metadataSet-28]
-	_ = x[metadataGet-29]
-	_ = x[metadataDel-30]
+	_ = x[machineSignal-12]
+	_ = x[machineFindLease-13]
+	_ = x[machineAcquireLease-14]
+	_ = x[machineRefreshLease-15]
+	_ = x[machineReleaseLease-16]
+	_ = x[machineExec-17]
+	_ = x[machinePs-18]
+	_ = x[machineCordon-19]
+	_ = x[machineUncordon-20]
+	_ = x[volumeList-21]
+	_ = x[volumeCreate-22]
+	_ = x[volumetUpdate-23]
+	_ = x[volumeGet-24]
+	_ = x[volumeSnapshotCreate-25]
+	_ = x[volumeSnapshotList-26]
+	_ = x[volumeExtend-27]
+	_ = x[volumeDelete-28]
+	_ = x[metadataSet-29]
+	_ = x[metadataGet-30]
+	_ = x[metadataDel-31]
 }
 
-const _flapsAction_name = "noneappCreatemachineLaunchmachineUpdatemachineStartmachineWaitmachineStopmachineRestartmachineGetmachineListmachineDestroymachineKillmachineFindLeasemachineAcquireLeasemachineRefreshLeasemachineReleaseLeasemachineExecmachinePsmachineCordonmachineUncordonvolumeListvolumeCreatevolumetUpdatevolumeGetvolumeSnapshotCreatevolumeSnapshotListvolumeExtendvolumeDeletemetadataSetmetadataGetmetadataDel"
+const _flapsAction_name = "noneappCreatemachineLaunchmachineUpdatemachineStartmachineWaitmachineStopmachineRestartmachineGetmachineListmachineDestroymachineKillmachineSignalmachineFindLeasemachineAcquireLeasemachineRefreshLeasemachineReleaseLeasemachineExecmachinePsmachineCordonmachineUncordonvolumeListvolumeCreatevolumetUpdatevolumeGetvolumeSnapshotCreatevolumeSnapshotListvolumeExtendvolumeDeletemetadataSetmetadataGetmetadataDel"
 
-var _flapsAction_index = [...]uint16{0, 4, 13, 26, 39, 51, 62, 73, 87, 97, 108, 122, 133, 149, 168, 187, 206, 217, 226, 239, 254, 264, 276, 289, 298, 318, 336, 348, 360, 371, 382, 393}
+var _flapsAction_index = [...]uint16{0, 4, 13, 26, 39, 51, 62, 73, 87, 97, 108, 122, 133, 146, 162, 181, 200, 219, 230, 239, 252, 267, 277, 289, 302, 311, 331, 349, 361, 373, 384, 395, 406}
 
 func (i flapsAction) String() string {
-	if i < 0 || i >= flapsAction(len(_flapsAction_index)-1) {
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_flapsAction_index)-1 {
 		return "flapsAction(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
-	return _flapsAction_name[_flapsAction_index[i]:_flapsAction_index[i+1]]
+	return _flapsAction_name[_flapsAction_index[idx]:_flapsAction_index[idx+1]]
 }
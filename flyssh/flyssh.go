@@ -0,0 +1,159 @@
+// Package flyssh runs commands and interactive shells on machines through
+// their SSH server, over the organization's private network.
+package flyssh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/fly-go/sshagent"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	Port        = 22
+	DefaultUser = "root"
+)
+
+// DialFunc connects to an address on the private network, for example
+// (*wg.Tunnel).DialContext.
+type DialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+type Client struct {
+	Dial        DialFunc
+	Certificate *fly.IssuedCertificate
+	// User to log in as. Defaults to root.
+	User string
+}
+
+// IO is the standard input and outputs of a remote command. Nil fields are
+// left unconnected.
+type IO struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// TTY requests a pseudo-terminal of the given size. Putting the local
+	// terminal in raw mode is up to the caller.
+	TTY  bool
+	Term string
+	Cols int
+	Rows int
+}
+
+// Addr returns the private network address of a machine's SSH server. With
+// an empty machineID, it returns the address of the nearest machine.
+func Addr(appName, machineID string) string {
+	host := fmt.Sprintf("top1.nearest.of.%s.internal", appName)
+	if machineID != "" {
+		host = fmt.Sprintf("%s.vm.%s.internal", machineID, appName)
+	}
+	return net.JoinHostPort(host, fmt.Sprint(Port))
+}
+
+// RunCommand runs cmd on a machine of appName and returns its exit code.
+func (c *Client) RunCommand(ctx context.Context, appName, machineID, cmd string, stdio IO) (int, error) {
+	return c.run(ctx, Addr(appName, machineID), cmd, stdio)
+}
+
+// Shell starts an interactive shell on a machine of appName and returns its
+// exit code once it ends.
+func (c *Client) Shell(ctx context.Context, appName, machineID string, stdio IO) (int, error) {
+	return c.run(ctx, Addr(appName, machineID), "", stdio)
+}
+
+func (c *Client) run(ctx context.Context, addr, cmd string, stdio IO) (int, error) {
+	client, err := c.connect(ctx, addr)
+	if err != nil {
+		return -1, err
+	}
+	defer client.Close()
+
+	sess, err := client.NewSession()
+	if err != nil {
+		return -1, fmt.Errorf("failed to open ssh session: %w", err)
+	}
+	defer sess.Close()
+
+	sess.Stdin = stdio.Stdin
+	sess.Stdout = stdio.Stdout
+	sess.Stderr = stdio.Stderr
+
+	if stdio.TTY {
+		term := stdio.Term
+		if term == "" {
+			term = "xterm"
+		}
+		if err := sess.RequestPty(term, stdio.Rows, stdio.Cols, ssh.TerminalModes{ssh.ECHO: 1}); err != nil {
+			return -1, fmt.Errorf("failed to allocate a pty: %w", err)
+		}
+	}
+
+	if cmd == "" {
+		err = sess.Shell()
+	} else {
+		err = sess.Start(cmd)
+	}
+	if err != nil {
+		return -1, fmt.Errorf("failed to start remote command: %w", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- sess.Wait() }()
+
+	select {
+	case <-ctx.Done():
+		_ = sess.Signal(ssh.SIGKILL)
+		return -1, ctx.Err()
+	case err = <-done:
+	}
+
+	var exitErr *ssh.ExitError
+	switch {
+	case err == nil:
+		return 0, nil
+	case errors.As(err, &exitErr):
+		return exitErr.ExitStatus(), nil
+	default:
+		return -1, err
+	}
+}
+
+func (c *Client) connect(ctx context.Context, addr string) (*ssh.Client, error) {
+	if c.Dial == nil {
+		return nil, errors.New("flyssh: a Dial function is required")
+	}
+
+	signer, err := sshagent.Signer(c.Certificate)
+	if err != nil {
+		return nil, err
+	}
+
+	user := c.User
+	if user == "" {
+		user = DefaultUser
+	}
+
+	conn, err := c.Dial(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, &ssh.ClientConfig{
+		User: user,
+		Auth: []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		// Machines are only reachable over the authenticated private
+		// network, host keys aren't published anywhere.
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("ssh handshake with %s failed: %w", addr, err)
+	}
+
+	return ssh.NewClient(sshConn, chans, reqs), nil
+}
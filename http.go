@@ -13,17 +13,11 @@ import (
 )
 
 func NewHTTPClient(logger Logger, transport http.RoundTripper) (*http.Client, error) {
-	retryTransport := rehttp.NewTransport(
-		transport,
-		rehttp.RetryAll(
-			rehttp.RetryMaxRetries(3),
-			rehttp.RetryAny(
-				rehttp.RetryTemporaryErr(),
-				rehttp.RetryStatuses(502, 503),
-			),
-		),
-		rehttp.ExpJitterDelay(100*time.Millisecond, 1*time.Second),
-	)
+	return newHTTPClient(logger, transport, currentRetryPolicy())
+}
+
+func newHTTPClient(logger Logger, transport http.RoundTripper, policy RetryPolicy) (*http.Client, error) {
+	retryTransport := rehttp.NewTransport(transport, policy.retryFn(), policy.delayFn())
 
 	if logger != nil {
 		return &http.Client{
@@ -39,6 +33,34 @@ func NewHTTPClient(logger Logger, transport http.RoundTripper) (*http.Client, er
 	}, nil
 }
 
+// ConnectionPoolOptions tunes connection reuse for clients making many
+// concurrent requests. Zero fields keep http.DefaultTransport's settings.
+type ConnectionPoolOptions struct {
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int
+	IdleConnTimeout     time.Duration
+}
+
+// NewPooledTransport returns a copy of http.DefaultTransport with the given
+// pool settings.
+func NewPooledTransport(opts ConnectionPoolOptions) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.MaxIdleConns > 0 {
+		t.MaxIdleConns = opts.MaxIdleConns
+	}
+	if opts.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+	}
+	if opts.MaxConnsPerHost > 0 {
+		t.MaxConnsPerHost = opts.MaxConnsPerHost
+	}
+	if opts.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = opts.IdleConnTimeout
+	}
+	return t
+}
+
 type LoggingTransport struct {
 	InnerTransport http.RoundTripper
 	Logger         Logger
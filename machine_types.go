@@ -48,6 +48,26 @@ type Machine struct {
 	LeaseNonce string                `json:"nonce,omitempty"`
 }
 
+// CreatedTime returns CreatedAt parsed, or the zero time if it's unset or
+// malformed. CreatedAt keeps the timestamp as sent by the API.
+func (m *Machine) CreatedTime() time.Time {
+	return parseMachineTime(m.CreatedAt)
+}
+
+// UpdatedTime returns UpdatedAt parsed, or the zero time if it's unset or
+// malformed.
+func (m *Machine) UpdatedTime() time.Time {
+	return parseMachineTime(m.UpdatedAt)
+}
+
+func parseMachineTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
 func (m *Machine) FullImageRef() string {
 	imgStr := fmt.Sprintf("%s/%s", m.ImageRef.Registry, m.ImageRef.Repository)
 	tag := m.ImageRef.Tag
@@ -284,6 +304,11 @@ type RestartMachineInput struct {
 	SkipHealthChecks bool          `json:"skip_health_checks,omitempty"`
 }
 
+type SignalMachineInput struct {
+	ID     string `json:"-"`
+	Signal string `json:"signal,omitempty"`
+}
+
 type MachineIP struct {
 	Family   string
 	Kind     string
@@ -429,6 +454,60 @@ var MachinePresets map[string]*MachineGuest = map[string]*MachineGuest{
 	"l40s":      {GPUKind: "l40s", GPUs: 1, CPUKind: "performance", CPUs: 8, MemoryMB: 16 * MIN_MEMORY_MB_PER_CPU},
 }
 
+const MEMORY_MB_INCREMENT = 256
+
+// GuestSize is a machine size preset along with the range of memory that
+// can be configured for it.
+type GuestSize struct {
+	Name        string
+	CPUKind     string
+	CPUs        int
+	GPUKind     string
+	GPUs        int
+	MemoryMB    int
+	MinMemoryMB int
+	MaxMemoryMB int
+}
+
+// MachineGuestSizes lists the machine size presets, shared CPUs first, then
+// performance CPUs, then GPUs, each by increasing CPU count. Memory can be
+// set between MinMemoryMB and MaxMemoryMB in steps of MEMORY_MB_INCREMENT.
+func MachineGuestSizes() []GuestSize {
+	sizes := make([]GuestSize, 0, len(MachinePresets))
+	for name, guest := range MachinePresets {
+		minPerCPU, maxPerCPU := MIN_MEMORY_MB_PER_CPU, MAX_MEMORY_MB_PER_CPU
+		if guest.CPUKind == "shared" {
+			minPerCPU, maxPerCPU = MIN_MEMORY_MB_PER_SHARED_CPU, MAX_MEMORY_MB_PER_SHARED_CPU
+		}
+
+		sizes = append(sizes, GuestSize{
+			Name:        name,
+			CPUKind:     guest.CPUKind,
+			CPUs:        guest.CPUs,
+			GPUKind:     guest.GPUKind,
+			GPUs:        guest.GPUs,
+			MemoryMB:    guest.MemoryMB,
+			MinMemoryMB: guest.CPUs * minPerCPU,
+			MaxMemoryMB: guest.CPUs * maxPerCPU,
+		})
+	}
+
+	sort.Slice(sizes, func(i, j int) bool {
+		a, b := sizes[i], sizes[j]
+		switch {
+		case a.GPUs != b.GPUs:
+			return a.GPUs < b.GPUs
+		case a.CPUKind != b.CPUKind:
+			return a.CPUKind == "shared"
+		case a.CPUs != b.CPUs:
+			return a.CPUs < b.CPUs
+		default:
+			return a.Name < b.Name
+		}
+	})
+	return sizes
+}
+
 type MachineMetrics struct {
 	Port int    `toml:"port" json:"port,omitempty"`
 	Path string `toml:"path" json:"path,omitempty"`
@@ -709,6 +788,11 @@ type MachineLeaseData struct {
 	Version   string `json:"version,omitempty"`
 }
 
+// ExpiresTime returns ExpiresAt, a unix timestamp in seconds, as a time.
+func (d *MachineLeaseData) ExpiresTime() time.Time {
+	return time.Unix(d.ExpiresAt, 0)
+}
+
 type MachineStartResponse struct {
 	Message       string `json:"message,omitempty"`
 	Status        string `json:"status,omitempty"`
@@ -1,6 +1,7 @@
 package fly
 
 import (
+	"reflect"
 	"testing"
 	"time"
 )
@@ -255,3 +256,38 @@ func TestMachineMostRecentStartTimeAfterLaunch(t *testing.T) {
 		}
 	}
 }
+
+func TestMachineGuestSizes(t *testing.T) {
+	sizes := MachineGuestSizes()
+	if len(sizes) != len(MachinePresets) {
+		t.Fatalf("got %d sizes, want %d", len(sizes), len(MachinePresets))
+	}
+
+	names := make([]string, 0, len(sizes))
+	for _, size := range sizes {
+		names = append(names, size.Name)
+		if size.MemoryMB < size.MinMemoryMB || size.MemoryMB > size.MaxMemoryMB {
+			t.Errorf("%s, got preset memory '%d', want between '%d' and '%d'", size.Name, size.MemoryMB, size.MinMemoryMB, size.MaxMemoryMB)
+		}
+	}
+
+	want := []string{
+		"shared-cpu-1x", "shared-cpu-2x", "shared-cpu-4x", "shared-cpu-8x",
+		"performance-1x", "performance-2x", "performance-4x", "performance-8x", "performance-16x",
+		"a100-40gb", "a100-80gb", "l40s",
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("got '%v', want '%v'", names, want)
+	}
+}
+
+func TestMachineTimes(t *testing.T) {
+	m := Machine{CreatedAt: "2024-03-01T12:30:00Z", UpdatedAt: "not a time"}
+
+	if got, want := m.CreatedTime(), time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("created, got '%v', want '%v'", got, want)
+	}
+	if got := m.UpdatedTime(); !got.IsZero() {
+		t.Errorf("updated, got '%v', want zero time", got)
+	}
+}
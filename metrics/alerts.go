@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type Comparison string
+
+const (
+	Above Comparison = ">"
+	Below Comparison = "<"
+)
+
+// AlertRule fires for each series of Expr whose value stays beyond
+// Threshold for the whole of Window. The platform has no hosted alerting, so
+// rules are evaluated with Evaluate, typically on a timer, and the caller
+// notifies Target of firing alerts.
+type AlertRule struct {
+	Name       string
+	Expr       string
+	Comparison Comparison
+	Threshold  float64
+	Window     time.Duration
+	// Step is the resolution Window is sampled at. Defaults to a tenth of
+	// Window, and at least 15 seconds.
+	Step time.Duration
+	// Target identifies where notifications should go, for example the name
+	// of a health check handler. It's passed through to alerts as is.
+	Target string
+}
+
+type Alert struct {
+	Rule   *AlertRule
+	Metric map[string]string
+	// Value is the most recent value of the series.
+	Value float64
+	// Since is the start of the window the series has breached the
+	// threshold for.
+	Since time.Time
+}
+
+// Evaluate returns the alerts rule fires at time now.
+func (c *Client) Evaluate(ctx context.Context, rule *AlertRule, now time.Time) ([]Alert, error) {
+	switch rule.Comparison {
+	case Above, Below:
+	default:
+		return nil, fmt.Errorf("metrics: invalid comparison '%s' in rule %s", rule.Comparison, rule.Name)
+	}
+
+	step := rule.Step
+	if step == 0 {
+		step = max(rule.Window/10, 15*time.Second)
+	}
+
+	series, err := c.QueryRange(ctx, rule.Expr, now.Add(-rule.Window), now, step)
+	if err != nil {
+		return nil, err
+	}
+	return firingAlerts(rule, series), nil
+}
+
+func firingAlerts(rule *AlertRule, series []Series) []Alert {
+	var alerts []Alert
+	for _, s := range series {
+		if len(s.Samples) == 0 {
+			continue
+		}
+
+		firing := true
+		for _, sample := range s.Samples {
+			if !rule.breached(sample.Value) {
+				firing = false
+				break
+			}
+		}
+		if !firing {
+			continue
+		}
+
+		alerts = append(alerts, Alert{
+			Rule:   rule,
+			Metric: s.Metric,
+			Value:  s.Samples[len(s.Samples)-1].Value,
+			Since:  s.Samples[0].Timestamp,
+		})
+	}
+	return alerts
+}
+
+func (r *AlertRule) breached(v float64) bool {
+	if r.Comparison == Below {
+		return v < r.Threshold
+	}
+	return v > r.Threshold
+}
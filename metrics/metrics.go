@@ -0,0 +1,217 @@
+// Package metrics queries the Prometheus-compatible metrics Fly.io collects
+// for an organization's machines and HTTP services.
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/fly-go/tokens"
+)
+
+type Client struct {
+	baseUrl    *url.URL
+	tokens     *tokens.Tokens
+	httpClient *http.Client
+	userAgent  string
+}
+
+type NewClientOpts struct {
+	// required:
+	OrgSlug string
+	Tokens  *tokens.Tokens
+
+	// optional, sent with requests
+	UserAgent string
+
+	// optional, defaults to FLY_PROMETHEUS_BASE_URL or https://api.fly.io/prometheus
+	BaseURL *url.URL
+
+	// optional:
+	Logger fly.Logger
+}
+
+func NewWithOptions(opts NewClientOpts) (*Client, error) {
+	if opts.OrgSlug == "" {
+		return nil, errors.New("metrics: an organization slug is required")
+	}
+
+	baseUrl := opts.BaseURL
+	if baseUrl == nil {
+		rawUrl := os.Getenv("FLY_PROMETHEUS_BASE_URL")
+		if rawUrl == "" {
+			rawUrl = "https://api.fly.io/prometheus"
+		}
+
+		var err error
+		baseUrl, err = url.Parse(rawUrl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid FLY_PROMETHEUS_BASE_URL '%s' with error: %w", rawUrl, err)
+		}
+	}
+
+	httpClient, err := fly.NewHTTPClient(opts.Logger, http.DefaultTransport)
+	if err != nil {
+		return nil, fmt.Errorf("metrics: can't setup HTTP client: %w", err)
+	}
+
+	userAgent := "fly-go"
+	if opts.UserAgent != "" {
+		userAgent = opts.UserAgent
+	}
+
+	return &Client{
+		baseUrl:    baseUrl.JoinPath(opts.OrgSlug),
+		tokens:     opts.Tokens,
+		httpClient: httpClient,
+		userAgent:  userAgent,
+	}, nil
+}
+
+// Sample is the value of a series at one point in time.
+type Sample struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// Series is a set of samples for one combination of labels. Instant
+// queries return series with a single sample.
+type Series struct {
+	Metric  map[string]string
+	Samples []Sample
+}
+
+// Query evaluates a PromQL expression at time at, or now if at is zero.
+func (c *Client) Query(ctx context.Context, query string, at time.Time) ([]Series, error) {
+	params := url.Values{"query": {query}}
+	if !at.IsZero() {
+		params.Set("time", formatTime(at))
+	}
+	return c.do(ctx, "query", params)
+}
+
+// QueryRange evaluates a PromQL expression every step between start and end.
+func (c *Client) QueryRange(ctx context.Context, query string, start, end time.Time, step time.Duration) ([]Series, error) {
+	params := url.Values{
+		"query": {query},
+		"start": {formatTime(start)},
+		"end":   {formatTime(end)},
+		"step":  {strconv.FormatFloat(step.Seconds(), 'f', -1, 64)},
+	}
+	return c.do(ctx, "query_range", params)
+}
+
+func (c *Client) do(ctx context.Context, endpoint string, params url.Values) ([]Series, error) {
+	u := c.baseUrl.JoinPath("api", "v1", endpoint)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.RawQuery = params.Encode()
+	req.Header.Set("User-Agent", c.userAgent)
+	if c.tokens != nil {
+		req.Header.Set("Authorization", c.tokens.FlapsHeader())
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeResponse(resp.StatusCode, body)
+}
+
+type apiResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Value  []any             `json:"value"`
+			Values [][]any           `json:"values"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+func decodeResponse(statusCode int, body []byte) ([]Series, error) {
+	var resp apiResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("metrics: failed to decode response with status %d: %w", statusCode, err)
+	}
+
+	if resp.Status != "success" {
+		return nil, fmt.Errorf("metrics: query failed with status %d: %s: %s", statusCode, resp.ErrorType, resp.Error)
+	}
+
+	switch resp.Data.ResultType {
+	case "vector", "matrix":
+	default:
+		return nil, fmt.Errorf("metrics: unsupported result type '%s'", resp.Data.ResultType)
+	}
+
+	series := make([]Series, 0, len(resp.Data.Result))
+	for _, result := range resp.Data.Result {
+		values := result.Values
+		if result.Value != nil {
+			values = [][]any{result.Value}
+		}
+
+		s := Series{Metric: result.Metric, Samples: make([]Sample, 0, len(values))}
+		for _, v := range values {
+			sample, err := decodeSample(v)
+			if err != nil {
+				return nil, err
+			}
+			s.Samples = append(s.Samples, sample)
+		}
+		series = append(series, s)
+	}
+
+	return series, nil
+}
+
+// decodeSample decodes a [<unix seconds>, "<value>"] pair.
+func decodeSample(v []any) (Sample, error) {
+	if len(v) != 2 {
+		return Sample{}, fmt.Errorf("metrics: invalid sample %v", v)
+	}
+
+	ts, ok := v[0].(float64)
+	if !ok {
+		return Sample{}, fmt.Errorf("metrics: invalid sample timestamp %v", v[0])
+	}
+	raw, ok := v[1].(string)
+	if !ok {
+		return Sample{}, fmt.Errorf("metrics: invalid sample value %v", v[1])
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return Sample{}, fmt.Errorf("metrics: invalid sample value %v: %w", v[1], err)
+	}
+
+	sec := int64(ts)
+	nsec := int64((ts - float64(sec)) * 1e9)
+	return Sample{Timestamp: time.Unix(sec, nsec).UTC(), Value: value}, nil
+}
+
+func formatTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixMilli())/1000, 'f', -1, 64)
+}
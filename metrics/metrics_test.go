@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDecodeResponse(t *testing.T) {
+	type testcase struct {
+		name    string
+		body    string
+		want    []Series
+		wantErr bool
+	}
+
+	cases := []testcase{
+		{
+			name: "vector",
+			body: `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"app":"web"},"value":[1700000000.5,"0.25"]}]}}`,
+			want: []Series{{
+				Metric:  map[string]string{"app": "web"},
+				Samples: []Sample{{Timestamp: time.Unix(1700000000, 5e8).UTC(), Value: 0.25}},
+			}},
+		},
+		{
+			name: "matrix",
+			body: `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{},"values":[[1700000000,"1"],[1700000015,"2"]]}]}}`,
+			want: []Series{{
+				Metric: map[string]string{},
+				Samples: []Sample{
+					{Timestamp: time.Unix(1700000000, 0).UTC(), Value: 1},
+					{Timestamp: time.Unix(1700000015, 0).UTC(), Value: 2},
+				},
+			}},
+		},
+		{
+			name:    "error",
+			body:    `{"status":"error","errorType":"bad_data","error":"parse error"}`,
+			wantErr: true,
+		},
+		{
+			name:    "scalar",
+			body:    `{"status":"success","data":{"resultType":"scalar","result":[]}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		got, err := decodeResponse(200, []byte(tc.body))
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s, got error '%v', want error '%v'", tc.name, err, tc.wantErr)
+			continue
+		}
+		if !tc.wantErr && !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s, got '%v', want '%v'", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestFiringAlerts(t *testing.T) {
+	rule := &AlertRule{Name: "cpu", Comparison: Above, Threshold: 0.8}
+	t0 := time.Unix(1700000000, 0).UTC()
+
+	series := []Series{
+		{Metric: map[string]string{"instance": "a"}, Samples: []Sample{{t0, 0.9}, {t0.Add(time.Minute), 0.95}}},
+		{Metric: map[string]string{"instance": "b"}, Samples: []Sample{{t0, 0.9}, {t0.Add(time.Minute), 0.5}}},
+		{Metric: map[string]string{"instance": "c"}},
+	}
+
+	want := []Alert{{Rule: rule, Metric: map[string]string{"instance": "a"}, Value: 0.95, Since: t0}}
+	if got := firingAlerts(rule, series); !reflect.DeepEqual(got, want) {
+		t.Errorf("got '%v', want '%v'", got, want)
+	}
+}
@@ -0,0 +1,91 @@
+package fly
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimitInfo is the rate limit state the API reported with a response.
+type RateLimitInfo struct {
+	Limit     int
+	Remaining int
+	// Reset is when the limit window resets, or zero if not reported.
+	Reset time.Time
+	// RetryAfter is how long to wait before retrying a rate limited
+	// request, or zero if not reported.
+	RetryAfter time.Duration
+}
+
+// rateLimitFromHeader parses the X-RateLimit-*, RateLimit-* and Retry-After
+// headers of a response.
+func rateLimitFromHeader(h http.Header, now time.Time) (RateLimitInfo, bool) {
+	var info RateLimitInfo
+	found := false
+
+	header := func(names ...string) (string, bool) {
+		for _, name := range names {
+			if v := h.Get(name); v != "" {
+				found = true
+				return v, true
+			}
+		}
+		return "", false
+	}
+
+	if v, ok := header("X-RateLimit-Limit", "RateLimit-Limit"); ok {
+		// RateLimit-Limit may carry a policy, as in "100, 100;w=60".
+		v, _, _ = strings.Cut(v, ",")
+		info.Limit, _ = strconv.Atoi(strings.TrimSpace(v))
+	}
+	if v, ok := header("X-RateLimit-Remaining", "RateLimit-Remaining"); ok {
+		info.Remaining, _ = strconv.Atoi(strings.TrimSpace(v))
+	}
+	if v, ok := header("X-RateLimit-Reset", "RateLimit-Reset"); ok {
+		if n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+			// Large values are Unix timestamps; small ones are seconds from
+			// now.
+			if n > 1e9 {
+				info.Reset = time.Unix(n, 0)
+			} else {
+				info.Reset = now.Add(time.Duration(n) * time.Second)
+			}
+		}
+	}
+	if v, ok := header("Retry-After"); ok {
+		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			info.RetryAfter = time.Duration(n) * time.Second
+		} else if t, err := http.ParseTime(v); err == nil {
+			info.RetryAfter = t.Sub(now)
+		}
+	}
+
+	return info, found
+}
+
+// rateLimitState holds the most recent rate limit info seen by a transport.
+type rateLimitState struct {
+	mu   sync.Mutex
+	info *RateLimitInfo
+}
+
+func (s *rateLimitState) observe(resp *http.Response) {
+	info, ok := rateLimitFromHeader(resp.Header, time.Now())
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.info = &info
+}
+
+func (s *rateLimitState) get() (RateLimitInfo, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.info == nil {
+		return RateLimitInfo{}, false
+	}
+	return *s.info, true
+}
@@ -0,0 +1,56 @@
+package fly
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimitFromHeader(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type testcase struct {
+		name   string
+		header http.Header
+		want   RateLimitInfo
+		found  bool
+	}
+
+	cases := []testcase{
+		{
+			name:   "none",
+			header: http.Header{},
+		},
+		{
+			name: "x-ratelimit",
+			header: http.Header{
+				"X-Ratelimit-Limit":     {"100"},
+				"X-Ratelimit-Remaining": {"7"},
+				"X-Ratelimit-Reset":     {"1704067260"},
+			},
+			want:  RateLimitInfo{Limit: 100, Remaining: 7, Reset: time.Unix(1704067260, 0)},
+			found: true,
+		},
+		{
+			name: "draft ratelimit",
+			header: http.Header{
+				"Ratelimit-Limit":     {"100, 100;w=60"},
+				"Ratelimit-Remaining": {"0"},
+				"Ratelimit-Reset":     {"30"},
+				"Retry-After":         {"30"},
+			},
+			want:  RateLimitInfo{Limit: 100, Remaining: 0, Reset: now.Add(30 * time.Second), RetryAfter: 30 * time.Second},
+			found: true,
+		},
+	}
+
+	for _, tc := range cases {
+		got, found := rateLimitFromHeader(tc.header, now)
+		if found != tc.found {
+			t.Errorf("%s, got '%v', want '%v'", tc.name, found, tc.found)
+		}
+		if got.Limit != tc.want.Limit || got.Remaining != tc.want.Remaining || !got.Reset.Equal(tc.want.Reset) || got.RetryAfter != tc.want.RetryAfter {
+			t.Errorf("%s, got '%+v', want '%+v'", tc.name, got, tc.want)
+		}
+	}
+}
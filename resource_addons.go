@@ -0,0 +1,210 @@
+package fly
+
+import (
+	"context"
+	"errors"
+)
+
+type AddOnType string
+
+const (
+	AddOnTypeUpstashRedis AddOnType = "upstash_redis"
+	AddOnTypeSentry       AddOnType = "sentry"
+)
+
+// AddOnFields are the fields queried for every add-on.
+const AddOnFields = `
+	id
+	name
+	status
+	errorMessage
+	primaryRegion
+	readRegions
+	options
+	publicUrl
+	privateIp
+	environment
+	addOnPlan {
+		id
+		name
+		displayName
+	}
+	organization {
+		id
+		slug
+	}
+`
+
+// CreateAddOn provisions an extension, such as a Redis database, for an
+// organization.
+func (c *Client) CreateAddOn(ctx context.Context, input CreateAddOnInput) (*AddOn, error) {
+	query := `
+		mutation($input: CreateAddOnInput!) {
+			createAddOn(input: $input) {
+				addOn {
+					` + AddOnFields + `
+				}
+			}
+		}
+	`
+
+	orgID, err := c.organizationID(ctx, input.OrganizationID, input.OrganizationSlug)
+	if err != nil {
+		return nil, err
+	}
+	input.OrganizationID = orgID
+
+	req := c.NewRequest(query)
+	req.Var("input", input)
+	ctx = ctxWithAction(ctx, "create_add_on")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.CreateAddOn.AddOn, nil
+}
+
+// CreateRedis provisions an Upstash Redis database. Its connection URL,
+// reachable over the organization's private network, is in the returned
+// add-on's PublicURL.
+func (c *Client) CreateRedis(ctx context.Context, input CreateRedisInput) (*AddOn, error) {
+	return c.CreateAddOn(ctx, CreateAddOnInput{
+		OrganizationID: input.OrganizationID,
+		Name:           input.Name,
+		PlanID:         input.PlanID,
+		PrimaryRegion:  input.PrimaryRegion,
+		ReadRegions:    input.ReadRegions,
+		Type:           AddOnTypeUpstashRedis,
+		Options:        map[string]any{"eviction": input.Eviction},
+	})
+}
+
+// CreateSentry provisions the Sentry extension for app and returns the DSN
+// of the Sentry project created for it. With setSecret, the DSN is also set
+// as the app's SENTRY_DSN secret, which triggers a new release.
+func (c *Client) CreateSentry(ctx context.Context, app *App, setSecret bool) (string, error) {
+	addOn, err := c.CreateAddOn(ctx, CreateAddOnInput{
+		OrganizationID: app.Organization.ID,
+		AppID:          app.ID,
+		Type:           AddOnTypeSentry,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	dsn, _ := addOn.Environment["SENTRY_DSN"].(string)
+	if dsn == "" {
+		return "", errors.New("sentry extension was created without a DSN")
+	}
+
+	if setSecret {
+		if _, err := c.SetSecrets(ctx, app.Name, map[string]string{"SENTRY_DSN": dsn}); err != nil {
+			return dsn, err
+		}
+	}
+
+	return dsn, nil
+}
+
+func (c *Client) GetAddOn(ctx context.Context, name string) (*AddOn, error) {
+	query := `
+		query($name: String!) {
+			addOn(name: $name) {
+				` + AddOnFields + `
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+	req.Var("name", name)
+	ctx = ctxWithAction(ctx, "get_add_on")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.AddOn == nil {
+		return nil, ErrNotFound
+	}
+
+	return data.AddOn, nil
+}
+
+// GetAddOnSSOLink returns a link that signs the viewer into the add-on
+// provider's dashboard for the add-on. Links are short-lived, so fetch one
+// right before sending the user to it.
+func (c *Client) GetAddOnSSOLink(ctx context.Context, name string) (string, error) {
+	query := `
+		query($name: String!) {
+			addOn(name: $name) {
+				ssoLink
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+	req.Var("name", name)
+	ctx = ctxWithAction(ctx, "get_add_on_sso_link")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	if data.AddOn == nil {
+		return "", ErrNotFound
+	}
+
+	return data.AddOn.SsoLink, nil
+}
+
+// GetAddOns returns an organization's add-ons of type addOnType.
+func (c *Client) GetAddOns(ctx context.Context, orgSlug string, addOnType AddOnType) ([]AddOn, error) {
+	query := `
+		query($slug: String!, $addOnType: AddOnType) {
+			organization(slug: $slug) {
+				addOns(type: $addOnType) {
+					nodes {
+						` + AddOnFields + `
+					}
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+	req.Var("slug", orgSlug)
+	req.Var("addOnType", addOnType)
+	ctx = ctxWithAction(ctx, "get_add_ons")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.Organization == nil {
+		return nil, ErrNotFound
+	}
+
+	return data.Organization.AddOns.Nodes, nil
+}
+
+func (c *Client) DeleteAddOn(ctx context.Context, name string) error {
+	query := `
+		mutation($input: DeleteAddOnInput!) {
+			deleteAddOn(input: $input) {
+				deletedAddOnName
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+	req.Var("input", DeleteAddOnInput{Name: name})
+	ctx = ctxWithAction(ctx, "delete_add_on")
+
+	_, err := c.RunWithContext(ctx, req)
+	return err
+}
@@ -0,0 +1,83 @@
+package fly
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// GetAppEvents returns the lifecycle events of an app, such as deploys,
+// scaling, restarts and secret changes, that happened after since, oldest
+// first. Events arrive newest first, so it pages back until it reaches one
+// from before since or runs out.
+func (c *Client) GetAppEvents(ctx context.Context, appName string, since time.Time) ([]AppEvent, error) {
+	events := []AppEvent{}
+	more := true
+	var cursor string
+
+	for more {
+		var page []AppEvent
+		var err error
+
+		page, more, cursor, err = c.getAppEventsPage(ctx, appName, cursor)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range page {
+			if event.CreatedAt.After(since) {
+				events = append(events, event)
+			} else {
+				more = false
+			}
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.Before(events[j].CreatedAt)
+	})
+
+	return events, nil
+}
+
+func (c *Client) getAppEventsPage(ctx context.Context, appName, after string) ([]AppEvent, bool, string, error) {
+	query := `
+		query($appName: String!, $after: String) {
+			app(name: $appName) {
+				changes(first: 100, after: $after) {
+					pageInfo {
+						hasNextPage
+						endCursor
+					}
+					nodes {
+						id
+						description
+						reason
+						status
+						createdAt
+						updatedAt
+						user {
+							id
+							name
+							email
+						}
+					}
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+	req.Var("appName", appName)
+	if after != "" {
+		req.Var("after", after)
+	}
+	ctx = ctxWithAction(ctx, "get_app_events")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, false, "", err
+	}
+
+	changes := data.App.Changes
+	return changes.Nodes, changes.PageInfo.HasNextPage, changes.PageInfo.EndCursor, nil
+}
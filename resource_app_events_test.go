@@ -0,0 +1,80 @@
+package fly
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetAppEvents(t *testing.T) {
+	// Three pages of events, newest first, one hour apart.
+	pages := map[string]struct {
+		ids  []int
+		next string
+	}{
+		"":   {ids: []int{6, 5}, next: "p2"},
+		"p2": {ids: []int{4, 3}, next: "p3"},
+		"p3": {ids: []int{2, 1}},
+	}
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Variables struct {
+				After string `json:"after"`
+			}
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		after := body.Variables.After
+		requests = append(requests, after)
+
+		page := pages[after]
+		var nodes []string
+		for _, id := range page.ids {
+			nodes = append(nodes, fmt.Sprintf(`{"id":"%d","createdAt":"%s"}`, id, base.Add(time.Duration(id)*time.Hour).Format(time.RFC3339)))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"data":{"app":{"changes":{"pageInfo":{"hasNextPage":%t,"endCursor":"%s"},"nodes":[%s]}}}}`,
+			page.next != "", page.next, strings.Join(nodes, ","))
+	}))
+	defer srv.Close()
+
+	c := NewClientFromOptions(ClientOptions{BaseURL: srv.URL})
+
+	type testcase struct {
+		name     string
+		since    time.Time
+		want     string
+		requests int
+	}
+
+	cases := []testcase{
+		{name: "second page", since: base.Add(3 * time.Hour), want: "4,5,6", requests: 2},
+		{name: "all pages", since: base, want: "1,2,3,4,5,6", requests: 3},
+	}
+
+	for _, tc := range cases {
+		requests = nil
+		events, err := c.GetAppEvents(context.Background(), "web", tc.since)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var ids []string
+		for _, e := range events {
+			ids = append(ids, e.ID)
+		}
+		if got := strings.Join(ids, ","); got != tc.want {
+			t.Errorf("%s, got '%v', want '%v'", tc.name, got, tc.want)
+		}
+		if len(requests) != tc.requests {
+			t.Errorf("%s, got '%v' requests, want '%v'", tc.name, len(requests), tc.requests)
+		}
+	}
+}
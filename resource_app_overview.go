@@ -0,0 +1,53 @@
+package fly
+
+import (
+	"context"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// AppOverview is everything a typical app page shows, fetched by
+// GetAppOverview.
+type AppOverview struct {
+	App            *AppCompact
+	CurrentRelease *Release
+	HealthChecks   []HealthCheckSummary
+	IPAddresses    []IPAddress
+	Certificates   []AppCertificateCompact
+}
+
+// GetAppOverview fetches an app along with its current release, health
+// checks, IP addresses and certificates, issuing the queries concurrently.
+// Machines apps have no separate deployment record; the state of the latest
+// deployment is CurrentRelease's Status and InProgress. If any query fails,
+// the others are canceled and the first error is returned.
+func (c *Client) GetAppOverview(ctx context.Context, appName string) (*AppOverview, error) {
+	var overview AppOverview
+	g, ctx := errgroup.WithContext(ctx)
+
+	g.Go(func() (err error) {
+		overview.App, err = c.GetAppCompact(ctx, appName)
+		return
+	})
+	g.Go(func() (err error) {
+		overview.CurrentRelease, err = c.GetAppCurrentReleaseMachines(ctx, appName)
+		return
+	})
+	g.Go(func() (err error) {
+		overview.HealthChecks, err = c.GetAppHealthChecks(ctx, appName)
+		return
+	})
+	g.Go(func() (err error) {
+		overview.IPAddresses, err = c.GetIPAddresses(ctx, appName)
+		return
+	})
+	g.Go(func() (err error) {
+		overview.Certificates, err = c.GetAppCertificates(ctx, appName)
+		return
+	})
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return &overview, nil
+}
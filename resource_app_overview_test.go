@@ -0,0 +1,69 @@
+package fly
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetAppOverview(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data":{
+			"app":{
+				"name":"web",
+				"currentRelease":{"version":3,"status":"running","inProgress":true},
+				"healthChecks":{"nodes":[]},
+				"ipAddresses":{"nodes":[{"address":"1.2.3.4","type":"v4"}]}
+			},
+			"appcompact":{"name":"web"},
+			"appcertscompact":{"certificates":{"nodes":[{"hostname":"example.com"}]}}
+		}}`)
+	}))
+	defer srv.Close()
+
+	c := NewClientFromOptions(ClientOptions{BaseURL: srv.URL})
+	overview, err := c.GetAppOverview(context.Background(), "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if overview.App.Name != "web" {
+		t.Errorf("app, got '%v', want '%v'", overview.App.Name, "web")
+	}
+	if overview.CurrentRelease.Status != "running" || !overview.CurrentRelease.InProgress {
+		t.Errorf("release, got '%+v', want a running release", overview.CurrentRelease)
+	}
+	if len(overview.IPAddresses) != 1 || overview.IPAddresses[0].Address != "1.2.3.4" {
+		t.Errorf("ips, got '%+v', want '1.2.3.4'", overview.IPAddresses)
+	}
+	if len(overview.Certificates) != 1 || overview.Certificates[0].Hostname != "example.com" {
+		t.Errorf("certificates, got '%+v', want 'example.com'", overview.Certificates)
+	}
+}
+
+func TestGetAppOverviewError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "ipAddresses") {
+			// Hold the other queries until the failure cancels them.
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data":null,"errors":[{"message":"ips unavailable"}]}`)
+	}))
+	defer srv.Close()
+
+	c := NewClientFromOptions(ClientOptions{BaseURL: srv.URL})
+	_, err := c.GetAppOverview(context.Background(), "web")
+	if err == nil || !strings.Contains(err.Error(), "ips unavailable") {
+		t.Errorf("got '%v', want 'ips unavailable'", err)
+	}
+	if errors.Is(err, context.Canceled) {
+		t.Errorf("got '%v', want the failure rather than a cancellation", err)
+	}
+}
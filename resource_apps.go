@@ -2,8 +2,57 @@ package fly
 
 import (
 	"context"
+	"slices"
+
+	"github.com/superfly/graphql"
 )
 
+type appQueryOptions struct {
+	volumes bool
+}
+
+func (o *appQueryOptions) apply(req *graphql.Request) {
+	req.Var("withVolumes", o.volumes)
+}
+
+// AppQueryOption selects optional data to include in GetApp and GetAppCompact.
+type AppQueryOption func(*appQueryOptions)
+
+// WithVolumes includes the app's volumes, along with the machine each is
+// attached to.
+var WithVolumes AppQueryOption = func(o *appQueryOptions) { o.volumes = true }
+
+func newAppQueryOptions(opts []AppQueryOption) *appQueryOptions {
+	o := new(appQueryOptions)
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+const appVolumesFragment = `
+				volumes @include(if: $withVolumes) {
+					nodes {
+						id
+						internalId
+						name
+						state
+						sizeGb
+						region
+						encrypted
+						createdAt
+						attachedMachine {
+							id
+							name
+							state
+						}
+						attachedAllocation {
+							id
+						}
+					}
+				}
+`
+
 func (client *Client) GetApps(ctx context.Context, role *string) ([]App, error) {
 	more := true
 	apps := []App{}
@@ -42,6 +91,93 @@ func (client *Client) GetAppsForOrganization(ctx context.Context, orgID string)
 	return apps, nil
 }
 
+// GetAppsForNetwork returns the apps of an organization attached to the
+// custom private network with the given name. Apps on the organization's
+// default network have an empty network name.
+func (client *Client) GetAppsForNetwork(ctx context.Context, orgID string, network string) ([]App, error) {
+	apps, err := client.GetAppsForOrganization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(apps, func(app App) bool {
+		return app.Network != network
+	}), nil
+}
+
+// GetOrganizationApps returns the apps of the organization with the given
+// slug, querying the organization directly rather than filtering the apps
+// visible to the token.
+func (client *Client) GetOrganizationApps(ctx context.Context, slug string) ([]App, error) {
+	more := true
+	apps := []App{}
+	var cursor string
+
+	for more {
+		var appPage []App
+		var err error
+
+		appPage, more, cursor, err = client.getOrganizationAppsPage(ctx, slug, &cursor)
+		if err != nil {
+			return nil, err
+		}
+		apps = append(apps, appPage...)
+	}
+
+	return apps, nil
+}
+
+func (client *Client) getOrganizationAppsPage(ctx context.Context, slug string, after *string) ([]App, bool, string, error) {
+	query := `
+		query($slug: String!, $after: String) {
+			organization(slug: $slug) {
+				apps(first: 200, after: $after) {
+					pageInfo {
+						hasNextPage
+						endCursor
+					}
+					nodes {
+						id
+						name
+						deployed
+						hostname
+						platformVersion
+						network
+						organization {
+							slug
+							name
+						}
+						currentRelease {
+							createdAt
+							status
+						}
+						status
+					}
+				}
+			}
+		}
+		`
+
+	req := client.NewRequest(query)
+	ctx = ctxWithAction(ctx, "get_organization_apps_page")
+	req.Var("slug", slug)
+	if after != nil {
+		req.Var("after", *after)
+	}
+
+	data, err := client.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, false, "", err
+	}
+
+	if data.Organization == nil {
+		return nil, false, "", ErrNotFound
+	}
+
+	apps := data.Organization.Apps
+	return apps.Nodes, apps.PageInfo.HasNextPage, apps.PageInfo.EndCursor, nil
+}
+
 func (client *Client) getAppsPage(ctx context.Context, orgID *string, role *string, after *string) ([]App, bool, string, error) {
 	query := `
 		query($org: ID, $role: String, $after: String) {
@@ -56,6 +192,7 @@ func (client *Client) getAppsPage(ctx context.Context, orgID *string, role *stri
 					deployed
 					hostname
 					platformVersion
+					network
 					organization {
 						slug
 						name
@@ -90,9 +227,9 @@ func (client *Client) getAppsPage(ctx context.Context, orgID *string, role *stri
 	return data.Apps.Nodes, data.Apps.PageInfo.HasNextPage, data.Apps.PageInfo.EndCursor, nil
 }
 
-func (client *Client) GetApp(ctx context.Context, appName string) (*App, error) {
+func (client *Client) GetApp(ctx context.Context, appName string, opts ...AppQueryOption) (*App, error) {
 	query := `
-		query ($appName: String!) {
+		query ($appName: String!, $withVolumes: Boolean!) {
 			app(name: $appName) {
 				id
 				name
@@ -102,6 +239,7 @@ func (client *Client) GetApp(ctx context.Context, appName string) (*App, error)
 				version
 				appUrl
 				platformVersion
+				network
 				currentRelease {
 					evaluationId
 					status
@@ -174,12 +312,14 @@ func (client *Client) GetApp(ctx context.Context, appName string) (*App, error)
 						expiresAt
 					}
 				}
+				` + appVolumesFragment + `
 			}
 		}
 	`
 
 	req := client.NewRequest(query)
 	req.Var("appName", appName)
+	newAppQueryOptions(opts).apply(req)
 	ctx = ctxWithAction(ctx, "get_app")
 
 	data, err := client.RunWithContext(ctx, req)
@@ -190,9 +330,9 @@ func (client *Client) GetApp(ctx context.Context, appName string) (*App, error)
 	return &data.App, nil
 }
 
-func (client *Client) GetAppCompact(ctx context.Context, appName string) (*AppCompact, error) {
+func (client *Client) GetAppCompact(ctx context.Context, appName string, opts ...AppQueryOption) (*AppCompact, error) {
 	query := `
-		query ($appName: String!) {
+		query ($appName: String!, $withVolumes: Boolean!) {
 			appcompact:app(name: $appName) {
 				id
 				name
@@ -209,12 +349,14 @@ func (client *Client) GetAppCompact(ctx context.Context, appName string) (*AppCo
 				postgresAppRole: role {
 					name
 				}
+				` + appVolumesFragment + `
 			}
 		}
 	`
 
 	req := client.NewRequest(query)
 	req.Var("appName", appName)
+	newAppQueryOptions(opts).apply(req)
 	ctx = ctxWithAction(ctx, "get_app_compact")
 
 	data, err := client.RunWithContext(ctx, req)
@@ -225,6 +367,49 @@ func (client *Client) GetAppCompact(ctx context.Context, appName string) (*AppCo
 	return &data.AppCompact, nil
 }
 
+// GetAppByID fetches an app by its global node ID, as referenced by other
+// API objects, rather than by name.
+func (client *Client) GetAppByID(ctx context.Context, id string) (*AppCompact, error) {
+	query := `
+		query ($id: ID!) {
+			appcompact: node(id: $id) {
+				... on App {
+					id
+					name
+					hostname
+					deployed
+					status
+					appUrl
+					platformVersion
+					organization {
+						id
+						slug
+						paidPlan
+					}
+					postgresAppRole: role {
+						name
+					}
+				}
+			}
+		}
+	`
+
+	req := client.NewRequest(query)
+	req.Var("id", id)
+	ctx = ctxWithAction(ctx, "get_app_by_id")
+
+	data, err := client.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.AppCompact.ID == "" {
+		return nil, ErrNotFound
+	}
+
+	return &data.AppCompact, nil
+}
+
 func (client *Client) GetAppBasic(ctx context.Context, appName string) (*AppBasic, error) {
 	query := `
 		query ($appName: String!) {
@@ -254,6 +439,8 @@ func (client *Client) GetAppBasic(ctx context.Context, appName string) (*AppBasi
 	return &data.AppBasic, nil
 }
 
+// CreateApp creates an app and returns it as provisioned, including its
+// hostname and any IP addresses allocated along with it.
 func (client *Client) CreateApp(ctx context.Context, input CreateAppInput) (*App, error) {
 	query := `
 		mutation($input: CreateAppInput!) {
@@ -261,21 +448,56 @@ func (client *Client) CreateApp(ctx context.Context, input CreateAppInput) (*App
 				app {
 					id
 					name
+					status
+					deployed
+					hostname
+					appUrl
+					platformVersion
+					network
 					organization {
+						id
 						slug
+						paidPlan
 					}
 					config {
 						definition
 					}
 					regions {
-							name
-							code
+						name
+						code
+					}
+					sharedIpAddress
+					ipAddresses {
+						nodes {
+							id
+							address
+							type
+							region
+							createdAt
+						}
 					}
 				}
 			}
 		}
 	`
 
+	if input.Name != "" {
+		if err := ValidateAppName(input.Name); err != nil {
+			return nil, err
+		}
+	}
+	if input.PreferredRegion != nil {
+		if err := ValidateRegionCode(*input.PreferredRegion); err != nil {
+			return nil, err
+		}
+	}
+
+	orgID, err := client.organizationID(ctx, input.OrganizationID, input.OrganizationSlug)
+	if err != nil {
+		return nil, err
+	}
+	input.OrganizationID = orgID
+
 	req := client.NewRequest(query)
 
 	req.Var("input", input)
@@ -309,6 +531,55 @@ func (client *Client) DeleteApp(ctx context.Context, appName string) error {
 	return err
 }
 
+// GetAppDeletionProtection reports whether an app is protected from
+// deletion.
+func (client *Client) GetAppDeletionProtection(ctx context.Context, appName string) (bool, error) {
+	query := `
+		query ($appName: String!) {
+			app(name: $appName) {
+				id
+				deletionProtection
+			}
+		}
+	`
+
+	req := client.NewRequest(query)
+	req.Var("appName", appName)
+	ctx = ctxWithAction(ctx, "get_app_deletion_protection")
+
+	data, err := client.RunWithContext(ctx, req)
+	if err != nil {
+		return false, err
+	}
+
+	return data.App.DeletionProtection, nil
+}
+
+// SetAppDeletionProtection turns deletion protection for an app on or off.
+// DeleteApp fails for protected apps.
+func (client *Client) SetAppDeletionProtection(ctx context.Context, appName string, enabled bool) error {
+	query := `
+		mutation ($input: SetAppDeletionProtectionInput!) {
+			setAppDeletionProtection(input: $input) {
+				app {
+					id
+					deletionProtection
+				}
+			}
+		}
+	`
+
+	req := client.NewRequest(query)
+	req.Var("input", SetAppDeletionProtectionInput{
+		AppID:   appName,
+		Enabled: enabled,
+	})
+	ctx = ctxWithAction(ctx, "set_app_deletion_protection")
+
+	_, err := client.RunWithContext(ctx, req)
+	return err
+}
+
 func (client *Client) MoveApp(ctx context.Context, appName string, orgID string) (*App, error) {
 	query := `
 		mutation ($input: MoveAppInput!) {
@@ -326,9 +597,9 @@ func (client *Client) MoveApp(ctx context.Context, appName string, orgID string)
 
 	req := client.NewRequest(query)
 
-	req.Var("input", map[string]string{
-		"appId":          appName,
-		"organizationId": orgID,
+	req.Var("input", MoveAppInput{
+		AppID:          appName,
+		OrganizationID: orgID,
 	})
 	ctx = ctxWithAction(ctx, "move_app")
 
@@ -0,0 +1,52 @@
+package fly
+
+import "context"
+
+// GetOrganizationBilling returns an organization's billing status, credit
+// balance and its most recent invoices, newest first.
+func (c *Client) GetOrganizationBilling(ctx context.Context, slug string, invoiceCount int) (*OrganizationBilling, error) {
+	query := `
+		query($slug: String!, $invoiceCount: Int!) {
+			organizationbilling: organization(slug: $slug) {
+				id
+				slug
+				paidPlan
+				billable
+				billingStatus
+				creditBalance
+				creditBalanceFormatted
+				isCreditCardSaved
+				invoices(first: $invoiceCount) {
+					nodes {
+						id
+						status
+						total
+						totalFormatted
+						currency
+						periodStart
+						periodEnd
+						dueDate
+						url
+					}
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+
+	req.Var("slug", slug)
+	req.Var("invoiceCount", invoiceCount)
+	ctx = ctxWithAction(ctx, "get_organization_billing")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.OrganizationBilling == nil {
+		return nil, ErrNotFound
+	}
+
+	return data.OrganizationBilling, nil
+}
@@ -0,0 +1,105 @@
+package fly
+
+import "context"
+
+// CreateBuild records the start of an image build for an app. The image
+// options, including its cache controls, are the ones the build runs with.
+// For buildpack builds, the returned build reports the builder and
+// buildpacks that were resolved.
+func (c *Client) CreateBuild(ctx context.Context, input CreateBuildInput) (*Build, error) {
+	query := `
+		mutation($input: CreateBuildInput!) {
+			createBuild(input: $input) {
+				id
+				status
+				builder
+				buildpacks
+			}
+		}
+	`
+
+	if input.StrategiesAvailable == nil {
+		input.StrategiesAvailable = []string{}
+	}
+
+	req := c.NewRequest(query)
+	req.Var("input", input)
+	ctx = ctxWithAction(ctx, "create_build")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.CreateBuild, nil
+}
+
+type BuildStatus string
+
+const (
+	BuildStatusInProgress BuildStatus = "in_progress"
+	BuildStatusFailed     BuildStatus = "failed"
+	BuildStatusSucceeded  BuildStatus = "succeeded"
+)
+
+const defaultBuildsLimit = 25
+
+// ListBuildsOptions filters and pages the builds returned by ListBuilds.
+type ListBuildsOptions struct {
+	// Status, when set, returns only builds in that state.
+	Status BuildStatus
+	// Limit is the page size. Defaults to 25.
+	Limit int
+	// After is the PageInfo.EndCursor of the previous page.
+	After string
+}
+
+// ListBuilds returns a page of an app's builds, newest first. Pass the
+// returned PageInfo.EndCursor as opts.After to fetch the next page.
+func (c *Client) ListBuilds(ctx context.Context, appName string, opts ListBuildsOptions) (*Connection[Build], error) {
+	query := `
+		query($appName: String!, $first: Int!, $after: String, $status: String) {
+			app(name: $appName) {
+				builds(first: $first, after: $after, status: $status) {
+					pageInfo {
+						hasNextPage
+						endCursor
+					}
+					nodes {
+						id
+						status
+						inProgress
+						image
+						builder
+						buildpacks
+						createdAt
+						updatedAt
+					}
+				}
+			}
+		}
+	`
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = defaultBuildsLimit
+	}
+
+	req := c.NewRequest(query)
+	req.Var("appName", appName)
+	req.Var("first", limit)
+	if opts.After != "" {
+		req.Var("after", opts.After)
+	}
+	if opts.Status != "" {
+		req.Var("status", opts.Status)
+	}
+	ctx = ctxWithAction(ctx, "list_builds")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &data.App.Builds, nil
+}
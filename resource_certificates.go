@@ -1,6 +1,10 @@
 package fly
 
-import "context"
+import (
+	"context"
+	"slices"
+	"strings"
+)
 
 func (c *Client) GetAppCertificates(ctx context.Context, appName string) ([]AppCertificateCompact, error) {
 	query := `
@@ -8,9 +12,19 @@ func (c *Client) GetAppCertificates(ctx context.Context, appName string) ([]AppC
 			appcertscompact:app(name: $appName) {
 				certificates {
 					nodes {
+						id
 						createdAt
 						hostname
 						clientStatus
+						configured
+						dnsValidationHostname
+						dnsValidationTarget
+						issued {
+							nodes {
+								type
+								expiresAt
+							}
+						}
 					}
 				}
 			}
@@ -30,6 +44,60 @@ func (c *Client) GetAppCertificates(ctx context.Context, appName string) ([]AppC
 	return data.AppCertsCompact.Certificates.Nodes, nil
 }
 
+// GetAppCertificate returns the full details of the certificate for hostname,
+// including every issued certificate and its expiry. It returns ErrNotFound
+// if the app has no certificate for hostname.
+func (c *Client) GetAppCertificate(ctx context.Context, appName, hostname string) (*AppCertificate, error) {
+	query := `
+		query($appName: String!, $hostname: String!) {
+			app(name: $appName) {
+				certificate(hostname: $hostname) {
+					acmeDnsConfigured
+					acmeAlpnConfigured
+					configured
+					certificateAuthority
+					createdAt
+					dnsProvider
+					dnsValidationInstructions
+					dnsValidationHostname
+					dnsValidationTarget
+					hostname
+					id
+					source
+					clientStatus
+					isApex
+					isWildcard
+					issued {
+						nodes {
+							type
+							expiresAt
+						}
+					}
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+
+	req.Var("appName", appName)
+	req.Var("hostname", hostname)
+	ctx = ctxWithAction(ctx, "get_app_certificate")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// A missing certificate comes back as null, which leaves the struct
+	// zeroed.
+	if data.App.Certificate.ID == "" {
+		return nil, ErrNotFound
+	}
+
+	return &data.App.Certificate, nil
+}
+
 func (c *Client) CheckAppCertificate(ctx context.Context, appName, hostname string) (*AppCertificate, *HostnameCheck, error) {
 	query := `
 		mutation($input: CheckCertificateInput!) {
@@ -72,9 +140,9 @@ func (c *Client) CheckAppCertificate(ctx context.Context, appName, hostname stri
 
 	req := c.NewRequest(query)
 
-	req.Var("input", map[string]string{
-		"appId":    appName,
-		"hostname": hostname,
+	req.Var("input", CheckCertificateInput{
+		AppID:    appName,
+		Hostname: hostname,
 	})
 	ctx = ctxWithAction(ctx, "check_app_certificates")
 
@@ -168,3 +236,134 @@ func (c *Client) DeleteCertificate(ctx context.Context, appName, hostname string
 
 	return &data.DeleteCertificate, nil
 }
+
+// DNSRecordRequirement is a DNS record that must exist for a certificate to
+// be issued.
+type DNSRecordRequirement struct {
+	Type  string
+	Name  string
+	Value string
+}
+
+type CertificateCheckResult struct {
+	Certificate *AppCertificate
+	Check       *HostnameCheck
+	// RequiredRecords lists the records the user still needs to create. It is
+	// empty once the certificate is configured.
+	RequiredRecords []DNSRecordRequirement
+}
+
+// CheckCertificate checks the state of a certificate and works out which DNS
+// records still need to be created for it to be issued.
+func (c *Client) CheckCertificate(ctx context.Context, appName, hostname string) (*CertificateCheckResult, error) {
+	query := `
+		mutation($input: CheckCertificateInput!) {
+			checkCertificate(input: $input) {
+				app {
+					hostname
+					sharedIpAddress
+					ipAddresses {
+						nodes {
+							address
+							type
+						}
+					}
+				}
+				certificate {
+					acmeDnsConfigured
+					acmeAlpnConfigured
+					configured
+					dnsValidationHostname
+					dnsValidationTarget
+					hostname
+					id
+					clientStatus
+					isApex
+					isWildcard
+				}
+				check {
+					aRecords
+					aaaaRecords
+					cnameRecords
+					resolvedAddresses
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+
+	req.Var("input", CheckCertificateInput{
+		AppID:    appName,
+		Hostname: hostname,
+	})
+	ctx = ctxWithAction(ctx, "check_certificate")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := data.CheckCertificate
+	return &CertificateCheckResult{
+		Certificate:     payload.Certificate,
+		Check:           payload.Check,
+		RequiredRecords: requiredCertificateRecords(payload.App, payload.Certificate, payload.Check),
+	}, nil
+}
+
+func requiredCertificateRecords(app *App, cert *AppCertificate, check *HostnameCheck) []DNSRecordRequirement {
+	if app == nil || cert == nil || cert.Configured {
+		return nil
+	}
+	if check == nil {
+		check = &HostnameCheck{}
+	}
+
+	var v4, v6 []string
+	if app.SharedIPAddress != "" {
+		v4 = append(v4, app.SharedIPAddress)
+	}
+	for _, ip := range app.IPAddresses.Nodes {
+		switch ip.Type {
+		case IPAddressTypeV4, IPAddressTypeSharedV4:
+			v4 = append(v4, ip.Address)
+		case IPAddressTypeV6:
+			v6 = append(v6, ip.Address)
+		}
+	}
+
+	pointsAtApp := slices.ContainsFunc(check.ARecords, func(a string) bool { return slices.Contains(v4, a) }) ||
+		slices.ContainsFunc(check.AAAARecords, func(a string) bool { return slices.Contains(v6, a) }) ||
+		slices.ContainsFunc(check.CNAMERecords, func(cname string) bool {
+			return strings.TrimSuffix(cname, ".") == app.Hostname
+		})
+
+	var records []DNSRecordRequirement
+	if !pointsAtApp {
+		name := strings.TrimPrefix(cert.Hostname, "*.")
+		if cert.IsApex {
+			for _, addr := range v4 {
+				records = append(records, DNSRecordRequirement{Type: "A", Name: name, Value: addr})
+			}
+			for _, addr := range v6 {
+				records = append(records, DNSRecordRequirement{Type: "AAAA", Name: name, Value: addr})
+			}
+		} else {
+			records = append(records, DNSRecordRequirement{Type: "CNAME", Name: cert.Hostname, Value: app.Hostname})
+		}
+	}
+
+	// Wildcards can only be validated with the DNS challenge; other hostnames
+	// can use TLS-ALPN once they point at the app.
+	needsDNSChallenge := cert.IsWildcard || (!pointsAtApp && !cert.AcmeALPNConfigured)
+	if needsDNSChallenge && !cert.AcmeDNSConfigured && cert.DNSValidationHostname != "" {
+		records = append(records, DNSRecordRequirement{
+			Type:  "CNAME",
+			Name:  cert.DNSValidationHostname,
+			Value: cert.DNSValidationTarget,
+		})
+	}
+
+	return records
+}
@@ -0,0 +1,101 @@
+package fly
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRequiredCertificateRecords(t *testing.T) {
+	app := &App{Hostname: "myapp.fly.dev"}
+	app.IPAddresses.Nodes = []IPAddress{
+		{Address: "1.2.3.4", Type: IPAddressTypeV4},
+		{Address: "2a09::1", Type: IPAddressTypeV6},
+		{Address: "fdaa::1", Type: IPAddressTypePrivateV6},
+	}
+	acme := DNSRecordRequirement{Type: "CNAME", Name: "_acme-challenge.example.com", Value: "example.com.abc.flydns.net"}
+
+	type testcase struct {
+		name  string
+		cert  *AppCertificate
+		check *HostnameCheck
+		want  []DNSRecordRequirement
+	}
+
+	cases := []testcase{
+		{
+			name: "configured",
+			cert: &AppCertificate{Hostname: "example.com", Configured: true},
+			want: nil,
+		},
+		{
+			name:  "apex not pointing at app",
+			cert:  &AppCertificate{Hostname: "example.com", IsApex: true, DNSValidationHostname: acme.Name, DNSValidationTarget: acme.Value},
+			check: &HostnameCheck{},
+			want: []DNSRecordRequirement{
+				{Type: "A", Name: "example.com", Value: "1.2.3.4"},
+				{Type: "AAAA", Name: "example.com", Value: "2a09::1"},
+				acme,
+			},
+		},
+		{
+			name:  "subdomain with cname to app",
+			cert:  &AppCertificate{Hostname: "www.example.com", DNSValidationHostname: acme.Name, DNSValidationTarget: acme.Value},
+			check: &HostnameCheck{CNAMERecords: []string{"myapp.fly.dev."}},
+			want:  nil,
+		},
+		{
+			name:  "subdomain not pointing at app",
+			cert:  &AppCertificate{Hostname: "www.example.com"},
+			check: &HostnameCheck{},
+			want:  []DNSRecordRequirement{{Type: "CNAME", Name: "www.example.com", Value: "myapp.fly.dev"}},
+		},
+		{
+			name:  "wildcard pointing at app still needs challenge",
+			cert:  &AppCertificate{Hostname: "*.example.com", IsWildcard: true, DNSValidationHostname: acme.Name, DNSValidationTarget: acme.Value},
+			check: &HostnameCheck{ARecords: []string{"1.2.3.4"}},
+			want:  []DNSRecordRequirement{acme},
+		},
+	}
+
+	for _, tc := range cases {
+		got := requiredCertificateRecords(app, tc.cert, tc.check)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s, got '%v', want '%v'", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestGetAppCertificate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(string(body), "missing.example.com") {
+			io.WriteString(w, `{"data":{"app":{"certificate":null}}}`)
+			return
+		}
+		io.WriteString(w, `{"data":{"app":{"certificate":{"id":"cert1","hostname":"example.com",`+
+			`"issued":{"nodes":[{"type":"rsa","expiresAt":"2030-01-01T00:00:00Z"}]}}}}}`)
+	}))
+	defer srv.Close()
+
+	c := NewClientFromOptions(ClientOptions{BaseURL: srv.URL})
+	ctx := context.Background()
+
+	cert, err := c.GetAppCertificate(ctx, "web", "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert.Hostname != "example.com" {
+		t.Errorf("got '%v', want '%v'", cert.Hostname, "example.com")
+	}
+
+	if _, err := c.GetAppCertificate(ctx, "web", "missing.example.com"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing hostname, got '%v', want '%v'", err, ErrNotFound)
+	}
+}
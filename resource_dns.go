@@ -39,7 +39,7 @@ func (c *Client) GetDNSRecords(ctx context.Context, domainName string) ([]*DNSRe
 		return nil, ErrNotFound
 	}
 
-	return *data.Domain.DnsRecords.Nodes, nil
+	return data.Domain.DNSRecordNodes(), nil
 }
 
 func (c *Client) ExportDNSRecords(ctx context.Context, domainId string) (string, error) {
@@ -53,9 +53,7 @@ func (c *Client) ExportDNSRecords(ctx context.Context, domainId string) (string,
 
 	req := c.NewRequest(query)
 
-	req.Var("input", map[string]interface{}{
-		"domainId": domainId,
-	})
+	req.Var("input", ExportDNSZoneInput{DomainID: domainId})
 	ctx = ctxWithAction(ctx, "export_dns_records")
 
 	data, err := c.RunWithContext(ctx, req)
@@ -91,9 +89,9 @@ func (c *Client) ImportDNSRecords(ctx context.Context, domainId string, zonefile
 
 	req := c.NewRequest(query)
 
-	req.Var("input", map[string]interface{}{
-		"domainId": domainId,
-		"zonefile": zonefile,
+	req.Var("input", ImportDNSZoneInput{
+		DomainID: domainId,
+		Zonefile: zonefile,
 	})
 	ctx = ctxWithAction(ctx, "import_dns_records")
 
@@ -104,3 +102,140 @@ func (c *Client) ImportDNSRecords(ctx context.Context, domainId string, zonefile
 
 	return data.ImportDnsZone.Warnings, data.ImportDnsZone.Changes, nil
 }
+
+// DNSRecordFields are the fields queried for every DNS record.
+const DNSRecordFields = `
+					id
+					fqdn
+					name
+					type
+					ttl
+					rdata
+					isApex
+					isWildcard
+					isSystem
+					createdAt
+					updatedAt
+`
+
+func (c *Client) CreateDNSRecord(ctx context.Context, input CreateDNSRecordInput) (*DNSRecord, error) {
+	query := `
+		mutation($input: CreateDNSRecordInput!) {
+			createDnsRecord(input: $input) {
+				record {
+					` + DNSRecordFields + `
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+
+	req.Var("input", input)
+	ctx = ctxWithAction(ctx, "create_dns_record")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.CreateDnsRecord.Record, nil
+}
+
+func (c *Client) UpdateDNSRecord(ctx context.Context, input UpdateDNSRecordInput) (*DNSRecord, error) {
+	query := `
+		mutation($input: UpdateDNSRecordInput!) {
+			updateDnsRecord(input: $input) {
+				record {
+					` + DNSRecordFields + `
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+
+	req.Var("input", input)
+	ctx = ctxWithAction(ctx, "update_dns_record")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.UpdateDnsRecord.Record, nil
+}
+
+func (c *Client) DeleteDNSRecord(ctx context.Context, recordId string) error {
+	query := `
+		mutation($input: DeleteDNSRecordInput!) {
+			deleteDnsRecord(input: $input) {
+				domain {
+					id
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+
+	req.Var("input", DeleteDNSRecordInput{RecordID: recordId})
+	ctx = ctxWithAction(ctx, "delete_dns_record")
+
+	_, err := c.RunWithContext(ctx, req)
+	return err
+}
+
+// ChangeDNSRecords applies changes to the records of a domain's zone as a
+// single atomic update: either every change is applied or none are. It
+// returns the resulting diff for each change.
+func (c *Client) ChangeDNSRecords(ctx context.Context, domainId string, changes []DNSRecordChangeInput) ([]DNSRecordDiff, error) {
+	query := `
+		mutation($input: UpdateDNSRecordsInput!) {
+			updateDnsRecords(input: $input) {
+				changes {
+					type
+					oldText
+					newText
+					oldAttributes {
+						name
+						type
+						ttl
+						rdata
+					}
+					newAttributes {
+						name
+						type
+						ttl
+						rdata
+					}
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+
+	req.Var("input", UpdateDNSRecordsInput{
+		DomainID: domainId,
+		Changes:  changes,
+	})
+	ctx = ctxWithAction(ctx, "change_dns_records")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.UpdateDnsRecords.Changes, nil
+}
+
+// ExportDNSRecordAttributes exports a domain's zone like ExportDNSRecords,
+// parsed into records.
+func (c *Client) ExportDNSRecordAttributes(ctx context.Context, domainId string) ([]DNSRecordAttributes, error) {
+	zonefile, err := c.ExportDNSRecords(ctx, domainId)
+	if err != nil {
+		return nil, err
+	}
+	return ParseZonefile(zonefile)
+}
@@ -2,19 +2,32 @@ package fly
 
 import "context"
 
+// DomainFields are the registration and DNS status fields shared by all
+// domain queries. Queries of your own can splice it into a domain selection
+// set, adding fields the Domain type doesn't model.
+const DomainFields = `
+	id
+	name
+	createdAt
+	updatedAt
+	registrationStatus
+	registrar
+	dnsStatus
+	dnssecStatus
+	serialNumber
+	autoRenew
+	expiresAt
+`
+
+// GetDomains returns the domains of an organization along with their
+// registration and DNS status.
 func (c *Client) GetDomains(ctx context.Context, organizationSlug string) ([]*Domain, error) {
 	query := `
 		query($slug: String!) {
 			organization(slug: $slug) {
 				domains {
 					nodes {
-						id
-						name
-						createdAt
-						registrationStatus
-						dnsStatus
-						autoRenew
-						expiresAt
+						` + DomainFields + `
 					}
 				}
 			}
@@ -30,20 +43,18 @@ func (c *Client) GetDomains(ctx context.Context, organizationSlug string) ([]*Do
 		return nil, err
 	}
 
-	return *data.Organization.Domains.Nodes, nil
+	if data.Organization == nil {
+		return nil, ErrNotFound
+	}
+
+	return data.Organization.DomainNodes(), nil
 }
 
 func (c *Client) GetDomain(ctx context.Context, name string) (*Domain, error) {
 	query := `
 		query($name: String!) {
 			domain(name: $name) {
-				id
-				name
-				createdAt
-				registrationStatus
-				dnsStatus
-				autoRenew
-				expiresAt
+				` + DomainFields + `
 				zoneNameservers
 				delegatedNameservers
 				organization {
@@ -72,13 +83,7 @@ func (c *Client) CreateDomain(organizationID string, name string) (*Domain, erro
 		mutation($input: CreateDomainInput!) {
 			createDomain(input: $input) {
 				domain {
-					id
-					name
-					createdAt
-					registrationStatus
-					dnsStatus
-					autoRenew
-					expiresAt
+					` + DomainFields + `
 				}
 			}
 		}
@@ -86,9 +91,9 @@ func (c *Client) CreateDomain(organizationID string, name string) (*Domain, erro
 
 	req := c.NewRequest(query)
 	ctx := ctxWithAction(context.Background(), "create_domain")
-	req.Var("input", map[string]interface{}{
-		"organizationId": organizationID,
-		"name":           name,
+	req.Var("input", CreateDomainInput{
+		OrganizationID: organizationID,
+		Name:           name,
 	})
 
 	data, err := c.RunWithContext(ctx, req)
@@ -117,7 +122,7 @@ func (c *Client) CheckDomain(ctx context.Context, name string) (*CheckDomainResu
 
 	req := c.NewRequest(query)
 	ctx = ctxWithAction(ctx, "check_domain")
-	req.Var("input", map[string]string{"domainName": name})
+	req.Var("input", CheckDomainInput{DomainName: name})
 
 	data, err := c.RunWithContext(ctx, req)
 	if err != nil {
@@ -128,28 +133,29 @@ func (c *Client) CheckDomain(ctx context.Context, name string) (*CheckDomainResu
 }
 
 func (c *Client) CreateAndRegisterDomain(organizationID string, name string) (*Domain, error) {
+	return c.RegisterDomain(context.Background(), CreateAndRegisterDomainInput{
+		OrganizationID: organizationID,
+		Name:           name,
+	})
+}
+
+// RegisterDomain registers a new domain through Fly.io and creates its zone.
+// Use CheckDomain first to find out whether the name is available and what
+// it costs.
+func (c *Client) RegisterDomain(ctx context.Context, input CreateAndRegisterDomainInput) (*Domain, error) {
 	query := `
 		mutation($input: CreateAndRegisterDomainInput!) {
 			createAndRegisterDomain(input: $input) {
 				domain {
-					id
-					name
-					createdAt
-					registrationStatus
-					dnsStatus
-					autoRenew
-					expiresAt
+					` + DomainFields + `
 				}
 			}
 		}
 	`
 
 	req := c.NewRequest(query)
-	ctx := ctxWithAction(context.Background(), "create_and_register_domain")
-	req.Var("input", map[string]interface{}{
-		"organizationId": organizationID,
-		"name":           name,
-	})
+	ctx = ctxWithAction(ctx, "create_and_register_domain")
+	req.Var("input", input)
 
 	data, err := c.RunWithContext(ctx, req)
 	if err != nil {
@@ -0,0 +1,174 @@
+package fly
+
+import (
+	"context"
+	"sort"
+)
+
+// GetAppHealthChecks returns the state of an app's health checks, grouped by
+// check name with the number of instances in each status.
+func (c *Client) GetAppHealthChecks(ctx context.Context, appName string) ([]HealthCheckSummary, error) {
+	query := `
+		query($appName: String!) {
+			app(name: $appName) {
+				healthChecks {
+					nodes {
+						name
+						status
+						output
+						serviceName
+						updatedAt
+						allocation {
+							id
+							region
+						}
+					}
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+	req.Var("appName", appName)
+	ctx = ctxWithAction(ctx, "get_app_health_checks")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return summarizeHealthChecks(data.App.HealthChecks.Nodes), nil
+}
+
+func summarizeHealthChecks(states []CheckState) []HealthCheckSummary {
+	byName := map[string]*HealthCheckSummary{}
+	for _, state := range states {
+		summary, ok := byName[state.Name]
+		if !ok {
+			summary = &HealthCheckSummary{Name: state.Name}
+			byName[state.Name] = summary
+		}
+
+		switch state.Status {
+		case "passing":
+			summary.Passing++
+		case "warning":
+			summary.Warning++
+		case "critical":
+			summary.Critical++
+		}
+		summary.States = append(summary.States, state)
+	}
+
+	summaries := make([]HealthCheckSummary, 0, len(byName))
+	for _, summary := range byName {
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Name < summaries[j].Name })
+
+	return summaries
+}
+
+// GetHealthCheckHandlers returns the handlers health check alerts are sent
+// to for an organization.
+func (c *Client) GetHealthCheckHandlers(ctx context.Context, orgSlug string) ([]HealthCheckHandler, error) {
+	query := `
+		query($slug: String!) {
+			organization(slug: $slug) {
+				healthCheckHandlers {
+					nodes {
+						name
+						type
+					}
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+	req.Var("slug", orgSlug)
+	ctx = ctxWithAction(ctx, "get_health_check_handlers")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.Organization == nil {
+		return nil, ErrNotFound
+	}
+
+	return data.Organization.HealthCheckHandlers.Nodes, nil
+}
+
+// SetSlackHealthCheckHandler creates or replaces a handler posting health
+// check alerts to a Slack webhook.
+func (c *Client) SetSlackHealthCheckHandler(ctx context.Context, input SetSlackHandlerInput) (*HealthCheckHandler, error) {
+	query := `
+		mutation($input: SetSlackHandlerInput!) {
+			setSlackHandler(input: $input) {
+				handler {
+					name
+					type
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+	req.Var("input", input)
+	ctx = ctxWithAction(ctx, "set_slack_health_check_handler")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.SetSlackHandler.Handler, nil
+}
+
+// SetPagerdutyHealthCheckHandler creates or replaces a handler opening
+// PagerDuty incidents for health check alerts.
+func (c *Client) SetPagerdutyHealthCheckHandler(ctx context.Context, input SetPagerdutyHandlerInput) (*HealthCheckHandler, error) {
+	query := `
+		mutation($input: SetPagerdutyHandlerInput!) {
+			setPagerdutyHandler(input: $input) {
+				handler {
+					name
+					type
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+	req.Var("input", input)
+	ctx = ctxWithAction(ctx, "set_pagerduty_health_check_handler")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.SetPagerdutyHandler.Handler, nil
+}
+
+func (c *Client) DeleteHealthCheckHandler(ctx context.Context, orgID, name string) error {
+	query := `
+		mutation($input: DeleteHealthCheckHandlerInput!) {
+			deleteHealthCheckHandler(input: $input) {
+				clientMutationId
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+	req.Var("input", DeleteHealthCheckHandlerInput{
+		OrganizationID: orgID,
+		Name:           name,
+	})
+	ctx = ctxWithAction(ctx, "delete_health_check_handler")
+
+	_, err := c.RunWithContext(ctx, req)
+	return err
+}
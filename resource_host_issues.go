@@ -0,0 +1,34 @@
+package fly
+
+import "context"
+
+// GetAppHostIssues returns open platform incidents on the hosts the app's
+// machines run on.
+func (c *Client) GetAppHostIssues(ctx context.Context, appName string) ([]HostIssue, error) {
+	query := `
+		query($appName: String!) {
+			app(name: $appName) {
+				hostIssues {
+					nodes {
+						internalId
+						message
+						createdAt
+						updatedAt
+					}
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+
+	req.Var("appName", appName)
+	ctx = ctxWithAction(ctx, "get_app_host_issues")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.App.HostIssues.Nodes, nil
+}
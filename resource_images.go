@@ -2,6 +2,18 @@ package fly
 
 import "context"
 
+const imageVersionFields = `
+	registry
+	repository
+	tag
+	digest
+	version
+`
+
+// GetLatestImageTag returns the most recent tag of an official image
+// repository, such as flyio/postgres-flex, so launchers don't have to
+// hard-code versions. With a snapshotId, the tag is the one compatible with
+// that volume snapshot. It returns ErrNotFound if the repository has no tags.
 func (client *Client) GetLatestImageTag(ctx context.Context, repository string, snapshotId *string) (string, error) {
 	query := `
 		query($repository: String!, $snapshotId: ID) {
@@ -18,6 +30,10 @@ func (client *Client) GetLatestImageTag(ctx context.Context, repository string,
 		return "", err
 	}
 
+	if data.LatestImageTag == "" {
+		return "", ErrNotFound
+	}
+
 	return data.LatestImageTag, nil
 }
 
@@ -45,3 +61,36 @@ func (client *Client) GetLatestImageDetails(ctx context.Context, image string) (
 	}
 	return &data.LatestImageDetails, nil
 }
+
+// GetAppImageUpdate reports whether a newer image than the one an app is
+// running is available in the repository the app's image came from.
+func (client *Client) GetAppImageUpdate(ctx context.Context, appName string) (*ImageUpdate, error) {
+	query := `
+		query($appName: String!) {
+			app(name: $appName) {
+				imageUpgradeAvailable
+				imageDetails {
+					` + imageVersionFields + `
+				}
+				latestImageDetails {
+					` + imageVersionFields + `
+				}
+			}
+		}
+	`
+
+	req := client.NewRequest(query)
+	req.Var("appName", appName)
+	ctx = ctxWithAction(ctx, "get_app_image_update")
+
+	data, err := client.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImageUpdate{
+		Current:   data.App.ImageDetails,
+		Latest:    data.App.LatestImageDetails,
+		Available: data.App.ImageUpgradeAvailable,
+	}, nil
+}
@@ -3,9 +3,20 @@ package fly
 import (
 	"context"
 	"net"
+	"slices"
 	"time"
 )
 
+const (
+	IPAddressTypeV4        = "v4"
+	IPAddressTypeV6        = "v6"
+	IPAddressTypePrivateV6 = "private_v6"
+	IPAddressTypeSharedV4  = "shared_v4"
+)
+
+// GetIPAddresses returns the IP addresses allocated to an app, without the
+// rest of the app data fetched by GetApp. The app's shared IPv4 address, if it
+// has one, is included with an empty ID.
 func (c *Client) GetIPAddresses(ctx context.Context, appName string) ([]IPAddress, error) {
 	query := `
 		query ($appName: String!) {
@@ -17,6 +28,10 @@ func (c *Client) GetIPAddresses(ctx context.Context, appName string) ([]IPAddres
 						type
 						region
 						createdAt
+						serviceName
+						network {
+							name
+						}
 					}
 				}
 				sharedIpAddress
@@ -40,7 +55,7 @@ func (c *Client) GetIPAddresses(ctx context.Context, appName string) ([]IPAddres
 		ips = append(ips, IPAddress{
 			ID:        "",
 			Address:   data.App.SharedIPAddress,
-			Type:      "shared_v4",
+			Type:      IPAddressTypeSharedV4,
 			Region:    "",
 			CreatedAt: time.Time{},
 		})
@@ -59,6 +74,10 @@ func (c *Client) AllocateIPAddress(ctx context.Context, appName string, addrType
 					type
 					region
 					createdAt
+					serviceName
+					network {
+						name
+					}
 				}
 			}
 		}
@@ -86,6 +105,27 @@ func (c *Client) AllocateIPAddress(ctx context.Context, appName string, addrType
 	return &data.AllocateIPAddress.IPAddress, nil
 }
 
+// GetFlycastIPAddresses returns only the app's Flycast addresses: private
+// IPv6 addresses that load balance across the app's machines over the
+// organization's private network.
+func (c *Client) GetFlycastIPAddresses(ctx context.Context, appName string) ([]IPAddress, error) {
+	ips, err := c.GetIPAddresses(ctx, appName)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(ips, func(ip IPAddress) bool {
+		return !ip.IsFlycast()
+	}), nil
+}
+
+// AllocateFlycastIPAddress allocates a Flycast address for an app. network is
+// the custom private network to allocate it on, or empty for the
+// organization's default network.
+func (c *Client) AllocateFlycastIPAddress(ctx context.Context, appName string, org *Organization, network string) (*IPAddress, error) {
+	return c.AllocateIPAddress(ctx, appName, IPAddressTypePrivateV6, "", org, network)
+}
+
 func (c *Client) AllocateSharedIPAddress(ctx context.Context, appName string) (net.IP, error) {
 	query := `
 		mutation($input: AllocateIPAddressInput!) {
@@ -99,7 +139,7 @@ func (c *Client) AllocateSharedIPAddress(ctx context.Context, appName string) (n
 
 	req := c.NewRequest(query)
 	ctx = ctxWithAction(ctx, "allocate_shared_ip_address")
-	req.Var("input", AllocateIPAddressInput{AppID: appName, Type: "shared_v4"})
+	req.Var("input", AllocateIPAddressInput{AppID: appName, Type: IPAddressTypeSharedV4})
 
 	data, err := c.RunWithContext(ctx, req)
 	if err != nil {
@@ -129,3 +169,22 @@ func (c *Client) ReleaseIPAddress(ctx context.Context, appName string, ip string
 
 	return nil
 }
+
+// ReleaseIPAddressByID releases an IP address given its ID, as returned by
+// GetIPAddresses or AllocateIPAddress.
+func (c *Client) ReleaseIPAddressByID(ctx context.Context, id string) error {
+	query := `
+		mutation($input: ReleaseIPAddressInput!) {
+			releaseIpAddress(input: $input) {
+				clientMutationId
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+	ctx = ctxWithAction(ctx, "release_ip_address_by_id")
+	req.Var("input", ReleaseIPAddressInput{IPAddressID: &id})
+
+	_, err := c.RunWithContext(ctx, req)
+	return err
+}
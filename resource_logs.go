@@ -3,22 +3,52 @@ package fly
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"slices"
+	"sort"
+	"time"
 )
 
-type getLogsResponse struct {
-	Data []struct {
-		Id         string
-		Attributes LogEntry
-	}
-	Meta struct {
-		NextToken string `json:"next_token"`
-	}
+// ErrResponseTooLarge is returned when a response body exceeds the client's
+// ClientOptions.MaxResponseBytes.
+var ErrResponseTooLarge = errors.New("response body too large")
+
+type logsResponseEntry struct {
+	Id         string
+	Attributes LogEntry
 }
 
 func (c *Client) GetAppLogs(ctx context.Context, appName, token, region, instanceID string) (entries []LogEntry, nextToken string, err error) {
+	nextToken, err = c.StreamAppLogs(ctx, appName, token, region, instanceID, func(entry LogEntry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	return
+}
+
+// StreamAppLogs is like GetAppLogs, but decodes the page incrementally and
+// calls fn with each entry as it is read instead of buffering the page.
+// Returning an error from fn stops reading and returns that error.
+func (c *Client) StreamAppLogs(ctx context.Context, appName, token, region, instanceID string, fn func(LogEntry) error) (nextToken string, err error) {
+	body, err := c.OpenAppLogs(ctx, appName, token, region, instanceID)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	return decodeLogs(body, fn)
+}
+
+// OpenAppLogs requests a page of logs like GetAppLogs and returns the
+// undecoded JSON:API response body, {"data": [...], "meta": {...}}, for
+// callers that forward or store pages as they are. Reads fail with
+// ErrResponseTooLarge past ClientOptions.MaxResponseBytes. The caller must
+// close the body.
+func (c *Client) OpenAppLogs(ctx context.Context, appName, token, region, instanceID string) (io.ReadCloser, error) {
 	data := url.Values{}
 	data.Set("next_token", token)
 	if instanceID != "" {
@@ -32,32 +62,188 @@ func (c *Client) GetAppLogs(ctx context.Context, appName, token, region, instanc
 
 	ctx = WithAuthorizationHeader(ctx, c.tokens.BubblegumHeader())
 
-	var req *http.Request
-	if req, err = http.NewRequestWithContext(ctx, "GET", url, nil); err != nil {
-		return
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	var result getLogsResponse
-
-	var res *http.Response
-	if res, err = c.httpClient.Do(req); err != nil {
-		return
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
 	}
-	defer res.Body.Close() //skipcq: GO-S2307
 
 	if res.StatusCode != 200 {
-		err = ErrorFromResp(res)
+		defer res.Body.Close() //skipcq: GO-S2307
+		return nil, ErrorFromResp(res)
+	}
+
+	return &limitedBody{Reader: c.limitBody(res.Body), Closer: res.Body}, nil
+}
+
+type limitedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// decodeLogs reads a JSON:API logs page, {"data": [...], "meta": {...}},
+// one entry at a time.
+func decodeLogs(r io.Reader, fn func(LogEntry) error) (string, error) {
+	dec := json.NewDecoder(r)
 
-		return
+	if err := expectDelim(dec, '{'); err != nil {
+		return "", err
 	}
 
-	if err = json.NewDecoder(res.Body).Decode(&result); err == nil {
-		nextToken = result.Meta.NextToken
+	var nextToken string
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return "", err
+		}
 
-		for _, d := range result.Data {
-			entries = append(entries, d.Attributes)
+		switch key {
+		case "data":
+			tok, err := dec.Token()
+			if err != nil {
+				return "", err
+			}
+			if tok == nil {
+				// An empty page is sometimes sent as "data": null.
+				continue
+			}
+			if tok != json.Delim('[') {
+				return "", fmt.Errorf("malformed logs response: expected '[', got '%v'", tok)
+			}
+			for dec.More() {
+				var entry logsResponseEntry
+				if err := dec.Decode(&entry); err != nil {
+					return "", err
+				}
+				if err := fn(entry.Attributes); err != nil {
+					return "", err
+				}
+			}
+			if err := expectDelim(dec, ']'); err != nil {
+				return "", err
+			}
+		case "meta":
+			var meta struct {
+				NextToken string `json:"next_token"`
+			}
+			if err := dec.Decode(&meta); err != nil {
+				return "", err
+			}
+			nextToken = meta.NextToken
+		default:
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return "", err
+			}
 		}
 	}
 
-	return
+	return nextToken, expectDelim(dec, '}')
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != want {
+		return fmt.Errorf("malformed logs response: expected '%v', got '%v'", want, tok)
+	}
+	return nil
+}
+
+// limitBody caps how much of a response body can be read, per
+// ClientOptions.MaxResponseBytes.
+func (c *Client) limitBody(r io.Reader) io.Reader {
+	if c.maxResponseBytes <= 0 {
+		return r
+	}
+	return &limitedReader{r: io.LimitReader(r, c.maxResponseBytes+1), remaining: c.maxResponseBytes}
+}
+
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, ErrResponseTooLarge
+	}
+	return n, err
+}
+
+// LogReorderer buffers log entries from overlapping polls, such as one
+// StreamAppLogs per region, and passes them on in timestamp order without
+// duplicates. An entry is held until one at least window newer has been
+// added, so window should cover how far out of order entries arrive.
+type LogReorderer struct {
+	window  time.Duration
+	emit    func(LogEntry) error
+	pending []LogEntry
+	seen    map[string]time.Time
+	newest  time.Time
+}
+
+// NewLogReorderer returns a LogReorderer that calls emit with each entry,
+// in order. Pass its Add method as the callback to StreamAppLogs, and call
+// Flush once done.
+func NewLogReorderer(window time.Duration, emit func(LogEntry) error) *LogReorderer {
+	return &LogReorderer{
+		window: window,
+		emit:   emit,
+		seen:   map[string]time.Time{},
+	}
+}
+
+// Add buffers entry, dropping it if it duplicates one already added, and
+// emits the entries that are now older than the window.
+func (r *LogReorderer) Add(entry LogEntry) error {
+	key := entry.Timestamp + "\x00" + entry.Instance + "\x00" + entry.Region + "\x00" + entry.Message
+	if _, ok := r.seen[key]; ok {
+		return nil
+	}
+
+	t := entry.Time()
+	r.seen[key] = t
+	if t.After(r.newest) {
+		r.newest = t
+	}
+
+	i := sort.Search(len(r.pending), func(i int) bool { return r.pending[i].Time().After(t) })
+	r.pending = slices.Insert(r.pending, i, entry)
+
+	return r.release(r.newest.Add(-r.window))
+}
+
+// Flush emits every buffered entry.
+func (r *LogReorderer) Flush() error {
+	return r.release(r.newest)
+}
+
+// release emits the entries up to watermark and forgets duplicates too old
+// to be emitted again anyway.
+func (r *LogReorderer) release(watermark time.Time) error {
+	n := 0
+	for n < len(r.pending) && !r.pending[n].Time().After(watermark) {
+		if err := r.emit(r.pending[n]); err != nil {
+			r.pending = r.pending[n+1:]
+			return err
+		}
+		n++
+	}
+	r.pending = r.pending[n:]
+
+	for key, t := range r.seen {
+		if t.Before(watermark.Add(-r.window)) {
+			delete(r.seen, key)
+		}
+	}
+	return nil
 }
@@ -0,0 +1,122 @@
+package fly
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeLogs(t *testing.T) {
+	body := `{"data":[{"id":"1","type":"log","attributes":{"timestamp":"2024-01-01T00:00:00Z","message":"one"}},` +
+		`{"id":"2","attributes":{"message":"two"}}],"meta":{"next_token":"abc"}}`
+
+	var messages []string
+	next, err := decodeLogs(strings.NewReader(body), func(e LogEntry) error {
+		messages = append(messages, e.Message)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != "abc" {
+		t.Errorf("next token, got '%v', want '%v'", next, "abc")
+	}
+	if got := strings.Join(messages, ","); got != "one,two" {
+		t.Errorf("messages, got '%v', want '%v'", got, "one,two")
+	}
+
+	stop := errors.New("stop")
+	if _, err := decodeLogs(strings.NewReader(body), func(LogEntry) error { return stop }); err != stop {
+		t.Errorf("callback error, got '%v', want '%v'", err, stop)
+	}
+
+	next, err = decodeLogs(strings.NewReader(`{"data":null,"meta":{"next_token":"abc"}}`), func(LogEntry) error {
+		t.Error("want no entries for a null page")
+		return nil
+	})
+	if err != nil || next != "abc" {
+		t.Errorf("null page, got '%v' '%v', want 'abc'", next, err)
+	}
+
+	c := &Client{maxResponseBytes: 10}
+	if _, err := decodeLogs(c.limitBody(strings.NewReader(body)), func(LogEntry) error { return nil }); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("limited body, got '%v', want '%v'", err, ErrResponseTooLarge)
+	}
+}
+
+func TestLogReorderer(t *testing.T) {
+	var messages []string
+	r := NewLogReorderer(2*time.Second, func(e LogEntry) error {
+		messages = append(messages, e.Message)
+		return nil
+	})
+
+	entries := []LogEntry{
+		{Timestamp: "2024-01-01T00:00:01Z", Message: "b", Region: "ord"},
+		{Timestamp: "2024-01-01T00:00:00Z", Message: "a", Region: "ams"},
+		{Timestamp: "2024-01-01T00:00:01Z", Message: "b", Region: "ord"},
+		{Timestamp: "2024-01-01T00:00:04Z", Message: "d", Region: "ord"},
+		{Timestamp: "2024-01-01T00:00:03Z", Message: "c", Region: "ams"},
+	}
+	for _, e := range entries {
+		if err := r.Add(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := strings.Join(messages, ","); got != "a,b" {
+		t.Errorf("before flush, got '%v', want '%v'", got, "a,b")
+	}
+
+	if err := r.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(messages, ","); got != "a,b,c,d" {
+		t.Errorf("after flush, got '%v', want '%v'", got, "a,b,c,d")
+	}
+}
+
+func TestOpenAppLogs(t *testing.T) {
+	page := `{"data":[{"id":"1","attributes":{"message":"one"}}],"meta":{"next_token":"abc"}}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/apps/web/logs" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, page)
+	}))
+	defer srv.Close()
+	defer SetBaseURL("")
+	SetBaseURL(srv.URL)
+
+	ctx := context.Background()
+
+	body, err := NewClientFromOptions(ClientOptions{}).OpenAppLogs(ctx, "web", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(body)
+	body.Close()
+	if err != nil || string(b) != page {
+		t.Errorf("got '%s' '%v', want '%s'", b, err, page)
+	}
+
+	body, err = NewClientFromOptions(ClientOptions{MaxResponseBytes: 10}).OpenAppLogs(ctx, "web", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = io.ReadAll(body)
+	body.Close()
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("limited body, got '%v', want '%v'", err, ErrResponseTooLarge)
+	}
+
+	if _, err := NewClientFromOptions(ClientOptions{}).OpenAppLogs(ctx, "missing", "", "", ""); err == nil {
+		t.Error("missing app, want an error")
+	}
+}
@@ -0,0 +1,104 @@
+package fly
+
+import (
+	"context"
+	"sync"
+)
+
+// orgIDCache maps organization slugs to IDs. Slugs of deleted organizations
+// can be reused, so entries are dropped when an organization is deleted.
+type orgIDCache struct {
+	mu  sync.Mutex
+	ids map[orgIDKey]string
+}
+
+// orgIDKey scopes a slug to the credentials it was resolved with, since
+// slugs like "personal" name a different organization for every user.
+type orgIDKey struct {
+	auth string
+	slug string
+}
+
+func orgIDKeyFor(ctx context.Context, slug string) orgIDKey {
+	auth, _ := ctx.Value(contextKeyAuthorization).(string)
+	return orgIDKey{auth: auth, slug: slug}
+}
+
+func (c *orgIDCache) get(key orgIDKey) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id, ok := c.ids[key]
+	return id, ok
+}
+
+func (c *orgIDCache) set(key orgIDKey, id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ids == nil {
+		c.ids = map[orgIDKey]string{}
+	}
+	c.ids[key] = id
+}
+
+func (c *orgIDCache) forget(match func(slug, id string) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, id := range c.ids {
+		if match(key.slug, id) {
+			delete(c.ids, key)
+		}
+	}
+}
+
+// ResolveOrganizationID returns the ID of the organization with the given
+// slug, as most mutations need. IDs are cached for the life of the client,
+// separately for each authorization set with WithAuthorizationHeader; see
+// ForgetOrganizationID.
+func (c *Client) ResolveOrganizationID(ctx context.Context, slug string) (string, error) {
+	if err := ValidateOrgSlug(slug); err != nil {
+		return "", err
+	}
+
+	key := orgIDKeyFor(ctx, slug)
+	if id, ok := c.orgIDs.get(key); ok {
+		return id, nil
+	}
+
+	query := `
+		query($slug: String!) {
+			organization(slug: $slug) {
+				id
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+	req.Var("slug", slug)
+	ctx = ctxWithAction(ctx, "resolve_organization_id")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	if data.Organization == nil {
+		return "", ErrNotFound
+	}
+
+	c.orgIDs.set(key, data.Organization.ID)
+	return data.Organization.ID, nil
+}
+
+// ForgetOrganizationID drops the cached IDs for slug, for when the
+// organization was deleted or renamed outside this client.
+func (c *Client) ForgetOrganizationID(slug string) {
+	c.orgIDs.forget(func(s, _ string) bool { return s == slug })
+}
+
+// organizationID returns id, or resolves slug when id is empty.
+func (c *Client) organizationID(ctx context.Context, id, slug string) (string, error) {
+	if id != "" || slug == "" {
+		return id, nil
+	}
+	return c.ResolveOrganizationID(ctx, slug)
+}
@@ -0,0 +1,92 @@
+package fly
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResolveOrganizationID(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data":{"organization":{"id":"org1"}}}`)
+	}))
+	defer srv.Close()
+
+	c := NewClientFromOptions(ClientOptions{BaseURL: srv.URL})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		id, err := c.ResolveOrganizationID(ctx, "personal")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != "org1" {
+			t.Errorf("got '%v', want '%v'", id, "org1")
+		}
+	}
+	if calls != 1 {
+		t.Errorf("cached lookup, got '%v' requests, want '%v'", calls, 1)
+	}
+
+	c.ForgetOrganizationID("personal")
+	if _, err := c.ResolveOrganizationID(ctx, "personal"); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("after forget, got '%v' requests, want '%v'", calls, 2)
+	}
+}
+
+func TestResolveOrganizationIDPerAuthorization(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"data":{"organization":{"id":"org-%s"}}}`, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	}))
+	defer srv.Close()
+
+	c := NewClientFromOptions(ClientOptions{BaseURL: srv.URL})
+
+	for _, user := range []string{"alice", "bob", "alice"} {
+		ctx := WithAuthorizationHeader(context.Background(), "Bearer "+user)
+		id, err := c.ResolveOrganizationID(ctx, "personal")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "org-" + user; id != want {
+			t.Errorf("%s, got '%v', want '%v'", user, id, want)
+		}
+	}
+}
+
+func TestCreateAppValidatesBeforeResolving(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data":{"organization":{"id":"org1"}}}`)
+	}))
+	defer srv.Close()
+
+	c := NewClientFromOptions(ClientOptions{BaseURL: srv.URL})
+	ctx := context.Background()
+
+	cases := []CreateAppInput{
+		{OrganizationSlug: "personal", Name: "Not_Valid"},
+		{OrganizationSlug: "Not Valid", Name: "web"},
+	}
+	for _, input := range cases {
+		if _, err := c.CreateApp(ctx, input); err == nil {
+			t.Errorf("%+v, want a validation error", input)
+		}
+	}
+	if calls != 0 {
+		t.Errorf("got '%v' requests, want '%v'", calls, 0)
+	}
+}
@@ -2,6 +2,9 @@ package fly
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
 
 	"github.com/superfly/graphql"
 )
@@ -13,22 +16,97 @@ const (
 	OrganizationTypeShared   OrganizationType = "SHARED"
 )
 
+type OrganizationMemberRole string
+
+const (
+	OrganizationMemberRoleAdmin  OrganizationMemberRole = "ADMIN"
+	OrganizationMemberRoleMember OrganizationMemberRole = "MEMBER"
+)
+
 type organizationFilter struct {
-	admin bool
+	admin   bool
+	orgType *OrganizationType
 }
 
 func (f *organizationFilter) apply(req *graphql.Request) {
 	req.Var("admin", f.admin)
+	if f.orgType != nil {
+		req.Var("type", *f.orgType)
+	}
 }
 
 type OrganizationFilter func(*organizationFilter)
 
 var AdminOnly OrganizationFilter = func(f *organizationFilter) { f.admin = true }
 
+// WithOrganizationType only returns organizations of type t.
+func WithOrganizationType(t OrganizationType) OrganizationFilter {
+	return func(f *organizationFilter) { f.orgType = &t }
+}
+
 func (client *Client) GetOrganizations(ctx context.Context, filters ...OrganizationFilter) ([]Organization, error) {
+	filter := new(organizationFilter)
+	for _, f := range filters {
+		f(filter)
+	}
+
+	more := true
+	orgs := []Organization{}
+	var cursor string
+
+	for more {
+		var orgPage []Organization
+		var err error
+
+		orgPage, more, cursor, err = client.getOrganizationsPage(ctx, filter, &cursor)
+		if err != nil {
+			return nil, err
+		}
+		orgs = append(orgs, orgPage...)
+	}
+
+	return orgs, nil
+}
+
+// ViewerOrganization is an organization the viewer belongs to, and the
+// viewer's role in it.
+type ViewerOrganization struct {
+	Organization Organization
+	Role         OrganizationMemberRole
+}
+
+// IsAdmin reports whether the viewer administers the organization, which
+// managing members, billing and tokens requires.
+func (o ViewerOrganization) IsAdmin() bool {
+	return o.Role == OrganizationMemberRoleAdmin
+}
+
+// GetViewerOrganizations returns every organization the viewer belongs to,
+// along with their role in each.
+func (client *Client) GetViewerOrganizations(ctx context.Context, filters ...OrganizationFilter) ([]ViewerOrganization, error) {
+	orgs, err := client.GetOrganizations(ctx, filters...)
+	if err != nil {
+		return nil, err
+	}
+
+	viewerOrgs := make([]ViewerOrganization, len(orgs))
+	for i, org := range orgs {
+		viewerOrgs[i] = ViewerOrganization{
+			Organization: org,
+			Role:         OrganizationMemberRole(strings.ToUpper(org.ViewerRole)),
+		}
+	}
+	return viewerOrgs, nil
+}
+
+func (client *Client) getOrganizationsPage(ctx context.Context, filter *organizationFilter, after *string) ([]Organization, bool, string, error) {
 	q := `
-		query($admin: Boolean!) {
-			organizations(admin: $admin) {
+		query($admin: Boolean!, $type: OrganizationType, $after: String) {
+			organizations(admin: $admin, type: $type, first: 200, after: $after) {
+				pageInfo {
+					hasNextPage
+					endCursor
+				}
 				nodes {
 					id
 					slug
@@ -42,24 +120,25 @@ func (client *Client) GetOrganizations(ctx context.Context, filters ...Organizat
 		}
 	`
 
-	filter := new(organizationFilter)
-	for _, f := range filters {
-		f(filter)
-	}
-
 	req := client.NewRequest(q)
 	filter.apply(req)
+	if after != nil {
+		req.Var("after", *after)
+	}
 
 	ctx = ctxWithAction(ctx, "get_organizations")
 
 	data, err := client.RunWithContext(ctx, req)
 	if err != nil {
-		return nil, err
+		return nil, false, "", err
 	}
 
-	return data.Organizations.Nodes, nil
+	return data.Organizations.Nodes, data.Organizations.PageInfo.HasNextPage, data.Organizations.PageInfo.EndCursor, nil
 }
 
+// GetOrganizationBySlug returns an organization along with its billing
+// status and the limited access tokens issued for it. Resource limits aren't
+// included, since the GraphQL organization type has no field for them.
 func (client *Client) GetOrganizationBySlug(ctx context.Context, slug string) (*Organization, error) {
 	q := `
 		query($slug: String!) {
@@ -69,17 +148,18 @@ func (client *Client) GetOrganizationBySlug(ctx context.Context, slug string) (*
 				slug
 				name
 				type
+				paidPlan
 				billable
-                limitedAccessTokens {
+				billingStatus
+				creditBalance
+				creditBalanceFormatted
+				isCreditCardSaved
+				viewerRole
+				limitedAccessTokens {
 					nodes {
-					    id
-					    name
-					    expiresAt
-						user {
-							email
-						}
+						` + LimitedAccessTokenFields + `
 					}
-                }
+				}
 			}
 		}
 	`
@@ -153,8 +233,8 @@ func (c *Client) CreateOrganization(ctx context.Context, organizationname string
 
 	req := c.NewRequest(query)
 
-	req.Var("input", map[string]string{
-		"name": organizationname,
+	req.Var("input", CreateOrganizationInput{
+		Name: organizationname,
 	})
 	ctx = ctxWithAction(ctx, "create_organization")
 
@@ -178,8 +258,8 @@ func (c *Client) DeleteOrganization(ctx context.Context, id string) (deletedid s
 
 	req := c.NewRequest(query)
 
-	req.Var("input", map[string]string{
-		"organizationId": id,
+	req.Var("input", DeleteOrganizationInput{
+		OrganizationID: id,
 	})
 
 	ctx = ctxWithAction(ctx, "delete_organization")
@@ -189,9 +269,12 @@ func (c *Client) DeleteOrganization(ctx context.Context, id string) (deletedid s
 		return "", err
 	}
 
+	c.orgIDs.forget(func(_, orgID string) bool { return orgID == id })
+
 	return data.DeleteOrganization.DeletedOrganizationId, nil
 }
 
+// CreateOrganizationInvite invites email to join the organization with ID id.
 func (c *Client) CreateOrganizationInvite(ctx context.Context, id, email string) (*Invitation, error) {
 	query := `
 	mutation($input: CreateOrganizationInvitationInput!){
@@ -211,9 +294,9 @@ func (c *Client) CreateOrganizationInvite(ctx context.Context, id, email string)
 
 	req := c.NewRequest(query)
 
-	req.Var("input", map[string]string{
-		"organizationId": id,
-		"email":          email,
+	req.Var("input", CreateOrganizationInvitationInput{
+		OrganizationID: id,
+		Email:          email,
 	})
 	ctx = ctxWithAction(ctx, "create_organization_invite")
 
@@ -225,11 +308,14 @@ func (c *Client) CreateOrganizationInvite(ctx context.Context, id, email string)
 	return &data.CreateOrganizationInvitation.Invitation, nil
 }
 
+// DeleteOrganizationMembership removes a user from an organization and
+// returns the organization's name and the removed user's email.
 func (c *Client) DeleteOrganizationMembership(ctx context.Context, orgId, userId string) (string, string, error) {
 	query := `
 	mutation($input: DeleteOrganizationMembershipInput!){
 		deleteOrganizationMembership(input: $input){
 		organization{
+		  name
 		  slug
 		}
 		user{
@@ -242,11 +328,11 @@ func (c *Client) DeleteOrganizationMembership(ctx context.Context, orgId, userId
 
 	req := c.NewRequest(query)
 
-	req.Var("input", map[string]string{
-		"userId":         userId,
-		"organizationId": orgId,
+	req.Var("input", DeleteOrganizationMembershipInput{
+		OrganizationID: orgId,
+		UserID:         userId,
 	})
-	ctx = ctxWithAction(ctx, "delete_organization")
+	ctx = ctxWithAction(ctx, "delete_organization_membership")
 
 	data, err := c.RunWithContext(ctx, req)
 	if err != nil {
@@ -255,3 +341,106 @@ func (c *Client) DeleteOrganizationMembership(ctx context.Context, orgId, userId
 
 	return data.DeleteOrganizationMembership.Organization.Name, data.DeleteOrganizationMembership.User.Email, nil
 }
+
+// UpdateOrganizationMembership changes the role of a member of an
+// organization.
+func (c *Client) UpdateOrganizationMembership(ctx context.Context, orgId, userId string, role OrganizationMemberRole) (*OrganizationMembership, error) {
+	query := `
+	mutation($input: UpdateOrganizationMembershipInput!){
+		updateOrganizationMembership(input: $input){
+		membership{
+		  role
+		  joinedAt
+		  user{
+		    id
+		    name
+		    email
+		  }
+		}
+	  }
+	}
+	`
+
+	req := c.NewRequest(query)
+
+	req.Var("input", UpdateOrganizationMembershipInput{
+		OrganizationID: orgId,
+		UserID:         userId,
+		Role:           role,
+	})
+	ctx = ctxWithAction(ctx, "update_organization_membership")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &data.UpdateOrganizationMembership.Membership, nil
+}
+
+// GetOrganizationSettings returns the organization-wide settings of the
+// organization with the given slug.
+func (c *Client) GetOrganizationSettings(ctx context.Context, slug string) (*OrganizationSettings, error) {
+	query := `
+		query($slug: String!) {
+			organization(slug: $slug) {
+				id
+				settings
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+	req.Var("slug", slug)
+	ctx = ctxWithAction(ctx, "get_organization_settings")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.Organization == nil {
+		return nil, ErrNotFound
+	}
+
+	return parseOrganizationSettings(data.Organization.Settings)
+}
+
+// UpdateOrganizationSettings changes the settings set in input and returns
+// the resulting settings.
+func (c *Client) UpdateOrganizationSettings(ctx context.Context, input UpdateOrganizationSettingsInput) (*OrganizationSettings, error) {
+	query := `
+		mutation($input: UpdateOrganizationSettingsInput!) {
+			updateOrganizationSettings(input: $input) {
+				organization {
+					id
+					settings
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+	req.Var("input", input)
+	ctx = ctxWithAction(ctx, "update_organization_settings")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseOrganizationSettings(data.UpdateOrganizationSettings.Organization.Settings)
+}
+
+func parseOrganizationSettings(raw map[string]any) (*OrganizationSettings, error) {
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var settings OrganizationSettings
+	if err := json.Unmarshal(b, &settings); err != nil {
+		return nil, fmt.Errorf("failed to decode organization settings: %w", err)
+	}
+	return &settings, nil
+}
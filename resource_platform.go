@@ -14,6 +14,10 @@ func (c *Client) PlatformRegions(ctx context.Context) ([]Region, *Region, error)
 					longitude
 					gatewayAvailable
 					requiresPaidPlan
+					volumesAvailable
+					gpuAvailable
+					paused
+					capacityConstrained
 				}
 			}
 		}
@@ -40,3 +44,38 @@ func (c *Client) PlatformRegions(ctx context.Context) ([]Region, *Region, error)
 
 	return data.Platform.Regions, requestRegion, nil
 }
+
+// GetOrganizationFeatureFlags returns the platform features enabled or
+// disabled for an organization, so callers can gate functionality up front.
+func (c *Client) GetOrganizationFeatureFlags(ctx context.Context, slug string) (FeatureFlags, error) {
+	query := `
+		query($slug: String!) {
+			organization(slug: $slug) {
+				id
+				featureFlags {
+					name
+					enabled
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+	req.Var("slug", slug)
+	ctx = ctxWithAction(ctx, "get_organization_feature_flags")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.Organization == nil {
+		return nil, ErrNotFound
+	}
+
+	flags := make(FeatureFlags, len(data.Organization.FeatureFlags))
+	for _, f := range data.Organization.FeatureFlags {
+		flags[f.Name] = f.Enabled
+	}
+	return flags, nil
+}
@@ -2,19 +2,58 @@ package fly
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
 )
 
+// CreatePostgresCluster provisions a new Postgres cluster app and returns the
+// credentials of its superuser.
+func (client *Client) CreatePostgresCluster(ctx context.Context, input CreatePostgresClusterInput) (*CreatePostgresClusterPayload, error) {
+	query := `
+		mutation($input: CreatePostgresClusterInput!) {
+			createPostgresCluster(input: $input) {
+				app {
+					id
+					name
+					organization {
+						slug
+					}
+				}
+				username
+				password
+				connectionString
+			}
+		}
+		`
+
+	req := client.NewRequest(query)
+	req.Var("input", input)
+	ctx = ctxWithAction(ctx, "create_postgres_cluster")
+
+	data, err := client.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.CreatePostgresCluster, nil
+}
+
+// AttachPostgresCluster creates a database and user for an app on a Postgres
+// cluster and sets the connection string as a secret on the app, named
+// input.VariableName or DATABASE_URL.
 func (client *Client) AttachPostgresCluster(ctx context.Context, input AttachPostgresClusterInput) (*AttachPostgresClusterPayload, error) {
 	query := `
 		mutation($input: AttachPostgresClusterInput!) {
 			attachPostgresCluster(input: $input) {
 				app {
+					id
 					name
 				}
 				postgresClusterApp {
+					id
 					name
 				}
-				environmentVariableName
 				connectionString
 				environmentVariableName
 			}
@@ -33,6 +72,8 @@ func (client *Client) AttachPostgresCluster(ctx context.Context, input AttachPos
 	return data.AttachPostgresCluster, nil
 }
 
+// DetachPostgresCluster removes an attachment created by
+// AttachPostgresCluster, along with the secret it set on the app.
 func (client *Client) DetachPostgresCluster(ctx context.Context, input DetachPostgresClusterInput) error {
 	query := `
 		mutation($input: DetachPostgresClusterInput!) {
@@ -50,6 +91,8 @@ func (client *Client) DetachPostgresCluster(ctx context.Context, input DetachPos
 	return err
 }
 
+// ListPostgresClusterAttachments returns the attachments of an app to a
+// Postgres cluster, for example to find the ID to detach.
 func (client *Client) ListPostgresClusterAttachments(ctx context.Context, appName, postgresAppName string) ([]*PostgresClusterAttachment, error) {
 	query := `
 		query($appName: String!, $postgresAppName: String!) {
@@ -77,6 +120,67 @@ func (client *Client) ListPostgresClusterAttachments(ctx context.Context, appNam
 	return data.PostgresAttachments.Nodes, nil
 }
 
+// GetPostgresClusters returns the Postgres cluster apps of an organization.
+func (client *Client) GetPostgresClusters(ctx context.Context, orgID string) ([]App, error) {
+	role := "postgres_cluster"
+	more := true
+	apps := []App{}
+	var cursor string
+
+	for more {
+		var appPage []App
+		var err error
+
+		appPage, more, cursor, err = client.getAppsPage(ctx, &orgID, &role, &cursor)
+		if err != nil {
+			return nil, err
+		}
+		apps = append(apps, appPage...)
+	}
+
+	return apps, nil
+}
+
+// GetPostgresClusterRole returns the databases and users of a Postgres
+// cluster app.
+func (client *Client) GetPostgresClusterRole(ctx context.Context, appName string) (*PostgresClusterAppRole, error) {
+	query := `
+		query($appName: String!) {
+			app(name: $appName) {
+				postgresAppRole: role {
+					name
+					... on PostgresClusterAppRole {
+						databases {
+							name
+							users
+						}
+						users {
+							username
+							isSuperuser
+							databases
+						}
+					}
+				}
+			}
+		}
+		`
+
+	req := client.NewRequest(query)
+	req.Var("appName", appName)
+	ctx = ctxWithAction(ctx, "get_postgres_cluster_role")
+
+	data, err := client.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.App.PostgresAppRole == nil || data.App.PostgresAppRole.Name != "postgres_cluster" {
+		return nil, fmt.Errorf("app %s is not a postgres cluster", appName)
+	}
+
+	return data.App.PostgresAppRole, nil
+}
+
 func (client *Client) EnablePostgresConsul(ctx context.Context, appName string) (*PostgresEnableConsulPayload, error) {
 	const query = `
 		mutation($appName: ID!) {
@@ -96,3 +200,59 @@ func (client *Client) EnablePostgresConsul(ctx context.Context, appName string)
 
 	return data.EnablePostgresConsul, nil
 }
+
+// GetPostgresImageUpdate reports whether a newer image is available for a
+// Postgres cluster app. The update is flagged as breaking when it changes the
+// Postgres major version, which requires migrating the data.
+func (client *Client) GetPostgresImageUpdate(ctx context.Context, appName string) (*PostgresImageUpdate, error) {
+	query := `
+		query($appName: String!) {
+			app(name: $appName) {
+				postgresAppRole: role {
+					name
+				}
+				imageUpgradeAvailable
+				imageDetails {
+					` + imageVersionFields + `
+				}
+				latestImageDetails {
+					` + imageVersionFields + `
+				}
+			}
+		}
+		`
+
+	req := client.NewRequest(query)
+	req.Var("appName", appName)
+	ctx = ctxWithAction(ctx, "get_postgres_image_update")
+
+	data, err := client.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	app := data.App
+	if app.PostgresAppRole == nil || app.PostgresAppRole.Name != "postgres_cluster" {
+		return nil, fmt.Errorf("app %s is not a postgres cluster", appName)
+	}
+
+	current, latest := app.ImageDetails.Tag, app.LatestImageDetails.Tag
+	return &PostgresImageUpdate{
+		ImageUpdate: ImageUpdate{
+			Current:   app.ImageDetails,
+			Latest:    app.LatestImageDetails,
+			Available: app.ImageUpgradeAvailable,
+		},
+		Breaking: app.ImageUpgradeAvailable && postgresMajorVersion(current) != postgresMajorVersion(latest),
+	}, nil
+}
+
+// postgresMajorVersion returns the major version in an image tag such as
+// "15.6", or "" if the tag doesn't start with one.
+func postgresMajorVersion(tag string) string {
+	major, _, _ := strings.Cut(tag, ".")
+	if _, err := strconv.Atoi(major); err != nil {
+		return ""
+	}
+	return major
+}
@@ -2,18 +2,41 @@ package fly
 
 import "context"
 
-func (c *Client) GetNearestRegion(ctx context.Context) (*Region, error) {
+type nearestRegionOptions struct {
+	wireguardGateway bool
+}
+
+type NearestRegionOption func(*nearestRegionOptions)
+
+// WithWireGuardGateway only considers regions running a WireGuard gateway.
+var WithWireGuardGateway NearestRegionOption = func(o *nearestRegionOptions) { o.wireguardGateway = true }
+
+// GetNearestRegion returns the region closest to the caller, for example to
+// place a new app's first machines.
+func (c *Client) GetNearestRegion(ctx context.Context, opts ...NearestRegionOption) (*Region, error) {
+	options := new(nearestRegionOptions)
+	for _, o := range opts {
+		o(options)
+	}
+
+	return c.nearestRegion(ctx, "get_nearest_regions", options)
+}
+
+func (c *Client) nearestRegion(ctx context.Context, action string, options *nearestRegionOptions) (*Region, error) {
 	req := c.NewRequest(`
-		query {
-			nearestRegion {
+		query($wireguardGateway: Boolean) {
+			nearestRegion(wireguardGateway: $wireguardGateway) {
 				code
 				name
 				gatewayAvailable
 			}
 		}
 `)
+	if options.wireguardGateway {
+		req.Var("wireguardGateway", true)
+	}
 
-	ctx = ctxWithAction(ctx, "get_nearest_regions")
+	ctx = ctxWithAction(ctx, action)
 
 	data, err := c.RunWithContext(ctx, req)
 	if err != nil {
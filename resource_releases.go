@@ -1,6 +1,29 @@
 package fly
 
-import "context"
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// ReleaseFields are the fields queried for every release. Queries of your
+// own can splice it into a release selection set, adding fields the Release
+// type doesn't model, and decode the response with RunInto.
+const ReleaseFields = `
+	id
+	version
+	description
+	reason
+	status
+	imageRef
+	stable
+	user {
+		id
+		email
+		name
+	}
+	createdAt
+`
 
 func (c *Client) GetAppReleasesMachines(ctx context.Context, appName, status string, limit int) ([]Release, error) {
 	query := `
@@ -8,21 +31,9 @@ func (c *Client) GetAppReleasesMachines(ctx context.Context, appName, status str
 			app(name: $appName) {
 				releases: releasesUnprocessed(first: $limit) {
 					nodes {
-			  			id
-			  			version
-			  			description
-			  			reason
-			  			status
-			  			imageRef
-			  			stable
-			  			user {
-							id
-							email
-							name
-						}
-			  			createdAt
+						` + ReleaseFields + `
 					}
-		  		}
+				}
 			}
 		}
 	`
@@ -48,19 +59,7 @@ func (c *Client) GetAppCurrentReleaseMachines(ctx context.Context, appName strin
 		query ($appName: String!) {
 			app(name: $appName) {
 				currentRelease: currentReleaseUnprocessed {
-					id
-					version
-					description
-					reason
-					status
-					imageRef
-					stable
-					user {
-						id
-						email
-						name
-					}
-					createdAt
+					` + ReleaseFields + `
 				}
 			}
 		}
@@ -77,3 +76,66 @@ func (c *Client) GetAppCurrentReleaseMachines(ctx context.Context, appName strin
 
 	return data.App.CurrentRelease, nil
 }
+
+// maxWatchedReleases caps how many releases WatchReleases fetches when it
+// falls behind.
+const maxWatchedReleases = 25
+
+// defaultWatchReleasesInterval is used by WatchReleases when it's given no
+// interval.
+const defaultWatchReleasesInterval = 5 * time.Second
+
+// WatchReleases calls fn with every release of an app created after the
+// call, oldest first, until ctx is done or fn returns an error. It polls the
+// current release every interval, or every five seconds if interval is zero
+// or less, and only lists releases when its version changes.
+func (c *Client) WatchReleases(ctx context.Context, appName string, interval time.Duration, fn func(Release) error) error {
+	if interval <= 0 {
+		interval = defaultWatchReleasesInterval
+	}
+
+	current, err := c.GetAppCurrentReleaseMachines(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	var last int
+	if current != nil {
+		last = current.Version
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		current, err := c.GetAppCurrentReleaseMachines(ctx, appName)
+		if err != nil {
+			return err
+		}
+		if current == nil || current.Version <= last {
+			continue
+		}
+
+		releases, err := c.GetAppReleasesMachines(ctx, appName, "", min(current.Version-last, maxWatchedReleases))
+		if err != nil {
+			return err
+		}
+		sort.Slice(releases, func(i, j int) bool { return releases[i].Version < releases[j].Version })
+
+		for _, release := range releases {
+			if release.Version <= last {
+				continue
+			}
+			if err := fn(release); err != nil {
+				return err
+			}
+			last = release.Version
+		}
+	}
+}
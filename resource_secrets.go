@@ -23,6 +23,10 @@ func (c *Client) SetSecrets(ctx context.Context, appName string, secrets map[str
 		}
 	`
 
+	if err := ValidateSecrets(secrets, c.maxSecretsSize); err != nil {
+		return nil, err
+	}
+
 	input := SetSecretsInput{AppID: appName}
 	for k, v := range secrets {
 		input.Secrets = append(input.Secrets, SetSecretsInputSecret{Key: k, Value: v})
@@ -0,0 +1,36 @@
+package fly
+
+import "context"
+
+// UpdateServices replaces the services an app exposes, without a deploy.
+func (c *Client) UpdateServices(ctx context.Context, appName string, services []ServiceInput) ([]Service, error) {
+	query := `
+		mutation($input: UpdateServicesInput!) {
+			updateServices(input: $input) {
+				app {
+					services {
+						description
+						protocol
+						internalPort
+						ports {
+							port
+							handlers
+						}
+					}
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+
+	req.Var("input", UpdateServicesInput{AppID: appName, Services: services})
+	ctx = ctxWithAction(ctx, "update_services")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.UpdateServices.App.Services, nil
+}
@@ -29,10 +29,40 @@ query($slug: String!) {
 		return nil, err
 	}
 
-	return data.Organization.LoggedCertificates.Nodes, nil
+	if data.Organization == nil {
+		return nil, ErrNotFound
+	}
+
+	return data.Organization.LoggedCertificateNodes(), nil
+}
+
+// SSHCertificateExtension is a standard OpenSSH certificate extension.
+type SSHCertificateExtension string
+
+const (
+	SSHExtensionPermitAgentForwarding SSHCertificateExtension = "permit-agent-forwarding"
+	SSHExtensionPermitPortForwarding  SSHCertificateExtension = "permit-port-forwarding"
+	SSHExtensionPermitPTY             SSHCertificateExtension = "permit-pty"
+	SSHExtensionPermitX11Forwarding   SSHCertificateExtension = "permit-X11-forwarding"
+	SSHExtensionPermitUserRC          SSHCertificateExtension = "permit-user-rc"
+)
+
+// SSHCertificateOption sets optional fields of the certificate issued by
+// IssueSSHCertificate.
+type SSHCertificateOption func(*IssueCertificateInput)
+
+// WithSSHExtensions grants the certificate the given extensions, such as
+// agent or port forwarding.
+func WithSSHExtensions(extensions ...SSHCertificateExtension) SSHCertificateOption {
+	return func(in *IssueCertificateInput) {
+		in.Extensions = append(in.Extensions, extensions...)
+	}
 }
 
-func (c *Client) IssueSSHCertificate(ctx context.Context, org OrganizationImpl, principals []string, appNames []string, valid_hours *int, publicKey ed25519.PublicKey) (*IssuedCertificate, error) {
+// IssueSSHCertificate issues a certificate valid for every user in
+// principals on the machines of appNames, or of every app in org if appNames
+// is empty.
+func (c *Client) IssueSSHCertificate(ctx context.Context, org OrganizationImpl, principals []string, appNames []string, valid_hours *int, publicKey ed25519.PublicKey, opts ...SSHCertificateOption) (*IssuedCertificate, error) {
 	req := c.NewRequest(`
 mutation($input: IssueCertificateInput!) {
   issueCertificate(input: $input) {
@@ -50,18 +80,19 @@ mutation($input: IssueCertificateInput!) {
 		pubStr = strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPub)))
 	}
 
-	inputs := map[string]interface{}{
-		"organizationId": org.GetID(),
-		"principals":     principals,
-		"appNames":       appNames,
-		"publicKey":      pubStr,
+	input := IssueCertificateInput{
+		OrganizationID: org.GetID(),
+		Principals:     principals,
+		AppNames:       appNames,
+		PublicKey:      pubStr,
+		ValidHours:     valid_hours,
 	}
 
-	if valid_hours != nil {
-		inputs["validHours"] = *valid_hours
+	for _, opt := range opts {
+		opt(&input)
 	}
 
-	req.Var("input", inputs)
+	req.Var("input", input)
 	ctx = ctxWithAction(ctx, "issue_ssh_certificates")
 
 	data, err := c.RunWithContext(ctx, req)
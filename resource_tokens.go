@@ -4,18 +4,24 @@ import (
 	"context"
 )
 
+// LimitedAccessTokenFields are the fields queried for every limited access
+// token, such as a deploy token.
+const LimitedAccessTokenFields = `
+	id
+	name
+	expiresAt
+	user {
+		email
+	}
+`
+
 func (c *Client) GetAppLimitedAccessTokens(ctx context.Context, appName string) ([]LimitedAccessToken, error) {
 	query := `
 		query ($appName: String!) {
 			app(name: $appName) {
 				limitedAccessTokens {
 					nodes {
-						id
-						name
-						expiresAt
-						user {
-							email
-						}
+						` + LimitedAccessTokenFields + `
 					}
 				}
 			}
@@ -31,9 +37,54 @@ func (c *Client) GetAppLimitedAccessTokens(ctx context.Context, appName string)
 		return nil, err
 	}
 
+	if data.App.LimitedAccessTokens == nil {
+		return nil, nil
+	}
+
 	return data.App.LimitedAccessTokens.Nodes, nil
 }
 
+// GetOrganizationLimitedAccessTokens returns the limited access tokens,
+// such as deploy and org tokens, issued for an organization, so stale ones
+// can be audited and revoked with RevokeLimitedAccessToken. Unlike the other
+// token queries, it also fetches each token's profile, creation time and
+// last use.
+func (c *Client) GetOrganizationLimitedAccessTokens(ctx context.Context, slug string) ([]LimitedAccessToken, error) {
+	query := `
+		query ($slug: String!) {
+			organization(slug: $slug) {
+				id
+				limitedAccessTokens {
+					nodes {
+						` + LimitedAccessTokenFields + `
+						profile
+						createdAt
+						lastUsedAt
+					}
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+	req.Var("slug", slug)
+	ctx = ctxWithAction(ctx, "get_organization_limited_access_tokens")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.Organization == nil {
+		return nil, ErrNotFound
+	}
+	if data.Organization.LimitedAccessTokens == nil {
+		return nil, nil
+	}
+
+	return data.Organization.LimitedAccessTokens.Nodes, nil
+}
+
 func (c *Client) RevokeLimitedAccessToken(ctx context.Context, id string) error {
 	query := `
 		mutation($input:DeleteLimitedAccessTokenInput!) {
@@ -44,8 +95,8 @@ func (c *Client) RevokeLimitedAccessToken(ctx context.Context, id string) error
 	`
 	req := c.NewRequest(query)
 
-	req.Var("input", map[string]interface{}{
-		"id": id,
+	req.Var("input", DeleteLimitedAccessTokenInput{
+		ID: id,
 	})
 	ctx = ctxWithAction(ctx, "revoke_limited_access_token")
 
@@ -0,0 +1,74 @@
+package fly
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// CreateTurbokuApp creates an app from a Heroku app, copying its config
+// vars and rebuilding its slug to run on Fly.io. The migration continues
+// after the app is created; use WaitForTurbokuApp to wait for it.
+func (c *Client) CreateTurbokuApp(ctx context.Context, input CreateTurbokuAppInput) (*App, error) {
+	query := `
+		mutation($input: CreateTurbokuAppInput!) {
+			createTurbokuApp(input: $input) {
+				app {
+					id
+					name
+					status
+					hostname
+					organization {
+						slug
+					}
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+	req.Var("input", input)
+	ctx = ctxWithAction(ctx, "create_turboku_app")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &data.CreateTurbokuApp.App, nil
+}
+
+// defaultTurbokuPollInterval is used by WaitForTurbokuApp when it's given
+// no interval.
+const defaultTurbokuPollInterval = 2 * time.Second
+
+// WaitForTurbokuApp polls an app created by CreateTurbokuApp every interval
+// until its first deployment finishes, and fails if the app ends up dead.
+// An interval of zero or less polls every two seconds.
+func (c *Client) WaitForTurbokuApp(ctx context.Context, appName string, interval time.Duration) (*AppCompact, error) {
+	if interval <= 0 {
+		interval = defaultTurbokuPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		app, err := c.GetAppCompact(ctx, appName)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case app.Status == "dead":
+			return app, fmt.Errorf("heroku migration of %s failed", appName)
+		case app.Deployed:
+			return app, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
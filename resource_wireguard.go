@@ -3,7 +3,7 @@ package fly
 import (
 	"context"
 	"fmt"
-	"os"
+	"slices"
 )
 
 func (c *Client) GetWireGuardPeer(ctx context.Context, slug, name string) (*WireGuardPeer, error) {
@@ -29,6 +29,10 @@ query($slug: String!, $name: String!) {
 		return nil, err
 	}
 
+	if data.Organization == nil {
+		return nil, ErrNotFound
+	}
+
 	// this graphql code is satanic
 	return data.Organization.WireGuardPeer, nil
 }
@@ -57,10 +61,116 @@ query($slug: String!) {
 		return nil, err
 	}
 
-	return *data.Organization.WireGuardPeers.Nodes, nil
+	if data.Organization == nil {
+		return nil, ErrNotFound
+	}
+
+	return data.Organization.WireGuardPeerNodes(), nil
 }
 
-func (c *Client) CreateWireGuardPeer(ctx context.Context, org *Organization, region, name, pubkey string) (*CreatedWireGuardPeer, error) {
+const wireGuardGatewayStatusFields = `
+      gatewayStatus {
+        endpoint
+        lastHandshake
+        sinceHandshake
+        rx
+        tx
+        added
+        sinceAdded
+        live
+        wgError
+      }
+`
+
+// GetWireGuardPeerStatus returns a peer along with its gateway status.
+func (c *Client) GetWireGuardPeerStatus(ctx context.Context, slug, name string) (*WireGuardPeer, error) {
+	req := c.NewRequest(`
+query($slug: String!, $name: String!) {
+  organization(slug: $slug) {
+    wireGuardPeer(name: $name) {
+      id
+      name
+      pubkey
+      region
+      peerip
+      ` + wireGuardGatewayStatusFields + `
+    }
+  }
+}
+`)
+	req.Var("slug", slug)
+	req.Var("name", name)
+	ctx = ctxWithAction(ctx, "get_wg_peer_status")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.Organization == nil {
+		return nil, ErrNotFound
+	}
+
+	return data.Organization.WireGuardPeer, nil
+}
+
+// GetWireGuardPeersStatus returns the gateway status of an organization's
+// peers in a single query. When names are given, only those peers are
+// returned.
+func (c *Client) GetWireGuardPeersStatus(ctx context.Context, slug string, names ...string) ([]*WireGuardPeer, error) {
+	req := c.NewRequest(`
+query($slug: String!) {
+  organization(slug: $slug) {
+    wireGuardPeers {
+      nodes {
+        id
+        name
+        pubkey
+        region
+        peerip
+        ` + wireGuardGatewayStatusFields + `
+      }
+    }
+  }
+}
+`)
+	req.Var("slug", slug)
+	ctx = ctxWithAction(ctx, "get_wg_peers_status")
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.Organization == nil {
+		return nil, ErrNotFound
+	}
+
+	peers := data.Organization.WireGuardPeerNodes()
+	if len(names) == 0 {
+		return peers, nil
+	}
+
+	return slices.DeleteFunc(peers, func(p *WireGuardPeer) bool {
+		return !slices.Contains(names, p.Name)
+	}), nil
+}
+
+// WireGuardPeerOption sets optional fields of the peer created by
+// CreateWireGuardPeer.
+type WireGuardPeerOption func(*AddWireGuardPeerInput)
+
+// WithNATS creates the peer through NATS rather than by calling the gateway
+// directly.
+var WithNATS WireGuardPeerOption = func(in *AddWireGuardPeerInput) { in.Nats = true }
+
+// WithPeerNetwork attaches the peer to the organization's custom private
+// network with the given name instead of its default network.
+func WithPeerNetwork(network string) WireGuardPeerOption {
+	return func(in *AddWireGuardPeerInput) { in.Network = &network }
+}
+
+func (c *Client) CreateWireGuardPeer(ctx context.Context, org *Organization, region, name, pubkey string, opts ...WireGuardPeerOption) (*CreatedWireGuardPeer, error) {
 	req := c.NewRequest(`
 mutation($input: AddWireGuardPeerInput!) {
   addWireGuardPeer(input: $input) {
@@ -71,25 +181,21 @@ mutation($input: AddWireGuardPeerInput!) {
 }
 `)
 
-	var nats bool
-
-	if os.Getenv("WG_NATS") != "" {
-		nats = true
-		fmt.Printf("Creating wiregard peer via NATS")
+	input := AddWireGuardPeerInput{
+		OrganizationID: org.ID,
+		Name:           name,
+		Pubkey:         pubkey,
 	}
 
-	inputs := map[string]interface{}{
-		"organizationId": org.ID,
-		"name":           name,
-		"pubkey":         pubkey,
-		"nats":           nats,
+	if region != "" {
+		input.Region = &region
 	}
 
-	if region != "" {
-		inputs["region"] = region
+	for _, opt := range opts {
+		opt(&input)
 	}
 
-	req.Var("input", inputs)
+	req.Var("input", input)
 	ctx = ctxWithAction(ctx, "create_wg_peers")
 
 	data, err := c.RunWithContext(ctx, req)
@@ -110,9 +216,9 @@ mutation($input: RemoveWireGuardPeerInput!) {
   }
 }
 `)
-	req.Var("input", map[string]interface{}{
-		"organizationId": org.ID,
-		"name":           name,
+	req.Var("input", RemoveWireGuardPeerInput{
+		OrganizationID: org.ID,
+		Name:           name,
 	})
 	ctx = ctxWithAction(ctx, "remove_wg_peer")
 
@@ -121,6 +227,36 @@ mutation($input: RemoveWireGuardPeerInput!) {
 	return err
 }
 
+// RotateWireGuardPeer replaces the peer named oldName with a new peer named
+// newName using pubkey. The new peer is created before the old one is
+// removed, so there is no window without a working peer. If removing the old
+// peer fails, the new peer is returned along with the error so the caller can
+// retry the removal.
+//
+// Peer names are unique, so rotating a peer under the same name removes the
+// old peer first; connections through it drop until the new one is up. Pass
+// the options the old peer was created with, such as WithPeerNetwork, to
+// keep the new one on the same network.
+func (c *Client) RotateWireGuardPeer(ctx context.Context, org *Organization, region, oldName, newName, pubkey string, opts ...WireGuardPeerOption) (*CreatedWireGuardPeer, error) {
+	if oldName == newName {
+		if err := c.RemoveWireGuardPeer(ctx, org, oldName); err != nil {
+			return nil, fmt.Errorf("failed to remove %s before recreating it: %w", oldName, err)
+		}
+		return c.CreateWireGuardPeer(ctx, org, region, newName, pubkey, opts...)
+	}
+
+	peer, err := c.CreateWireGuardPeer(ctx, org, region, newName, pubkey, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.RemoveWireGuardPeer(ctx, org, oldName); err != nil {
+		return peer, fmt.Errorf("created peer %s but failed to remove %s: %w", newName, oldName, err)
+	}
+
+	return peer, nil
+}
+
 func (c *Client) CreateDelegatedWireGuardToken(ctx context.Context, org *Organization, name string) (*DelegatedWireGuardToken, error) {
 	req := c.NewRequest(`
 mutation($input: CreateDelegatedWireGuardTokenInput!) {
@@ -129,9 +265,9 @@ mutation($input: CreateDelegatedWireGuardTokenInput!) {
   }
 }
 `)
-	req.Var("input", map[string]interface{}{
-		"organizationId": org.ID,
-		"name":           name,
+	req.Var("input", CreateDelegatedWireGuardTokenInput{
+		OrganizationID: org.ID,
+		Name:           name,
 	})
 	ctx = ctxWithAction(ctx, "create_deletegated_wg_token")
 
@@ -152,18 +288,16 @@ mutation($input: DeleteDelegatedWireGuardTokenInput!) {
 }
 `
 
-	input := map[string]interface{}{
-		"organizationId": org.ID,
+	input := DeleteDelegatedWireGuardTokenInput{
+		OrganizationID: org.ID,
 	}
 
 	if name != nil {
-		input["name"] = *name
+		input.Name = name
 	} else {
-		input["token"] = *token
+		input.Token = token
 	}
 
-	fmt.Printf("%+v\n", input)
-
 	req := c.NewRequest(query)
 	req.Var("input", input)
 	ctx = ctxWithAction(ctx, "delete_deletegated_wg_token")
@@ -193,27 +327,52 @@ query($slug: String!) {
 		return nil, err
 	}
 
-	return *data.Organization.DelegatedWireGuardTokens.Nodes, nil
+	if data.Organization == nil {
+		return nil, ErrNotFound
+	}
+
+	return data.Organization.DelegatedWireGuardTokenNodes(), nil
 }
 
-func (c *Client) ClosestWireguardGatewayRegion(ctx context.Context) (*Region, error) {
+// GetDelegatedWireGuardTokenDetails is GetDelegatedWireGuardTokens with
+// each token's creation time, creator and last use, for auditing stale
+// tokens.
+func (c *Client) GetDelegatedWireGuardTokenDetails(ctx context.Context, slug string) ([]*DelegatedWireGuardTokenHandle, error) {
 	req := c.NewRequest(`
-		query {
-			nearestRegion(wireguardGateway: true) {
-				code
-				name
-				gatewayAvailable
-			}
-		}
+query($slug: String!) {
+  organization(slug: $slug) {
+    delegatedWireGuardTokens {
+      nodes {
+        name
+        createdAt
+        lastUsedAt
+        createdBy {
+          id
+          email
+          name
+        }
+      }
+    }
+  }
+}
 `)
-	ctx = ctxWithAction(ctx, "closest_wg_gateway_region")
+	req.Var("slug", slug)
+	ctx = ctxWithAction(ctx, "get_delegated_wg_token_details")
 
 	data, err := c.RunWithContext(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	return data.NearestRegion, nil
+	if data.Organization == nil {
+		return nil, ErrNotFound
+	}
+
+	return data.Organization.DelegatedWireGuardTokenNodes(), nil
+}
+
+func (c *Client) ClosestWireguardGatewayRegion(ctx context.Context) (*Region, error) {
+	return c.nearestRegion(ctx, "closest_wg_gateway_region", &nearestRegionOptions{wireguardGateway: true})
 }
 
 func (c *Client) ValidateWireGuardPeers(ctx context.Context, peerIPs []string) (invalid []string, err error) {
@@ -225,9 +384,7 @@ mutation($input: ValidateWireGuardPeersInput!) {
 }
 `)
 
-	req.Var("input", map[string]interface{}{
-		"peerIps": peerIPs,
-	})
+	req.Var("input", ValidateWireGuardPeersInput{PeerIPs: peerIPs})
 	ctx = ctxWithAction(ctx, "validate_wg_peers")
 
 	data, err := c.RunWithContext(ctx, req)
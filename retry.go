@@ -0,0 +1,131 @@
+package fly
+
+import (
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/rehttp"
+)
+
+// RetryPolicy controls how HTTP clients created by NewHTTPClient retry
+// temporary failures. Delays between attempts use full jitter: a random
+// duration between zero and BaseDelay doubled for every attempt, capped at
+// MaxDelay.
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+
+	// Disabled turns retries off, since a zero MaxRetries means the
+	// default.
+	Disabled bool
+
+	// Budget, when set, limits retries across every client sharing it, so
+	// a fleet of processes doesn't multiply load on an API that's already
+	// failing.
+	Budget *RetryBudget
+}
+
+var defaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	BaseDelay:  100 * time.Millisecond,
+	MaxDelay:   1 * time.Second,
+}
+
+var (
+	retryPolicyMu sync.Mutex
+	retryPolicy   = defaultRetryPolicy
+)
+
+// SetRetryPolicy sets the retry policy of clients created afterwards, other
+// than those given their own in ClientOptions. Zero fields keep their
+// defaults.
+func SetRetryPolicy(p RetryPolicy) {
+	retryPolicyMu.Lock()
+	defer retryPolicyMu.Unlock()
+	retryPolicy = p.withDefaults()
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxRetries == 0 {
+		p.MaxRetries = defaultRetryPolicy.MaxRetries
+	}
+	if p.BaseDelay == 0 {
+		p.BaseDelay = defaultRetryPolicy.BaseDelay
+	}
+	if p.MaxDelay == 0 {
+		p.MaxDelay = defaultRetryPolicy.MaxDelay
+	}
+	return p
+}
+
+func currentRetryPolicy() RetryPolicy {
+	retryPolicyMu.Lock()
+	defer retryPolicyMu.Unlock()
+	return retryPolicy
+}
+
+// RetryBudget caps retries at a fraction of requests. Every request adds
+// ratio to the budget, up to burst, and every retry spends one from it;
+// once the budget is spent, failures are returned without retrying until
+// enough requests have gone through to earn more.
+type RetryBudget struct {
+	mu      sync.Mutex
+	ratio   float64
+	burst   float64
+	balance float64
+}
+
+// NewRetryBudget returns a budget that lets retries make up at most ratio of
+// requests, such as 0.1 for 10%, after an initial burst of retries.
+func NewRetryBudget(ratio float64, burst int) *RetryBudget {
+	return &RetryBudget{
+		ratio:   ratio,
+		burst:   float64(burst),
+		balance: float64(burst),
+	}
+}
+
+func (b *RetryBudget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.balance = min(b.balance+b.ratio, b.burst)
+}
+
+func (b *RetryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.balance < 1 {
+		return false
+	}
+	b.balance--
+	return true
+}
+
+func (p RetryPolicy) retryFn() rehttp.RetryFn {
+	if p.Disabled {
+		return func(rehttp.Attempt) bool { return false }
+	}
+
+	retry := rehttp.RetryAll(
+		rehttp.RetryMaxRetries(p.MaxRetries),
+		rehttp.RetryAny(
+			rehttp.RetryTemporaryErr(),
+			rehttp.RetryStatuses(502, 503),
+		),
+	)
+	if p.Budget == nil {
+		return retry
+	}
+
+	return func(attempt rehttp.Attempt) bool {
+		if attempt.Index == 0 {
+			p.Budget.deposit()
+		}
+		return retry(attempt) && p.Budget.withdraw()
+	}
+}
+
+func (p RetryPolicy) delayFn() rehttp.DelayFn {
+	return rehttp.ExpJitterDelay(p.BaseDelay, p.MaxDelay)
+}
@@ -0,0 +1,70 @@
+package fly
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/rehttp"
+)
+
+func TestRetryBudget(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 3, Budget: NewRetryBudget(0.5, 1)}
+	retry := policy.retryFn()
+
+	unavailable := func(index int) rehttp.Attempt {
+		return rehttp.Attempt{
+			Index:    index,
+			Request:  &http.Request{Method: http.MethodGet},
+			Response: &http.Response{StatusCode: 503},
+		}
+	}
+
+	// The burst covers the first retry, and the request's own deposit is
+	// capped at the burst.
+	if !retry(unavailable(0)) {
+		t.Error("want first retry to be allowed")
+	}
+	if retry(unavailable(1)) {
+		t.Error("want retry over budget to be refused")
+	}
+
+	// Two more requests earn one more retry.
+	if retry(unavailable(0)) {
+		t.Error("want retry with half a token to be refused")
+	}
+	if !retry(unavailable(0)) {
+		t.Error("want retry to be allowed once a token is earned")
+	}
+}
+
+func TestClientRetryPolicy(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		name   string
+		policy RetryPolicy
+		calls  int
+	}{
+		{name: "disabled", policy: RetryPolicy{Disabled: true}, calls: 1},
+		{name: "two retries", policy: RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}, calls: 3},
+	}
+
+	for _, tc := range cases {
+		calls = 0
+		c := NewClientFromOptions(ClientOptions{BaseURL: srv.URL, RetryPolicy: &tc.policy})
+		if _, err := c.RunWithContext(context.Background(), c.NewRequest(`query { viewer { id } }`)); err == nil {
+			t.Errorf("%s, want an error", tc.name)
+		}
+		if calls != tc.calls {
+			t.Errorf("%s, got '%v', want '%v'", tc.name, calls, tc.calls)
+		}
+	}
+}
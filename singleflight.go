@@ -0,0 +1,77 @@
+package fly
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/superfly/graphql"
+)
+
+// run sends req, deduplicating it against identical in-flight queries when
+// the client was created with DeduplicateQueries. Mutations and uploads are
+// always sent.
+func (c *Client) run(ctx context.Context, req *graphql.Request) (Query, error) {
+	if c.inflight == nil || c.getRequestType(req) != "query" || len(req.Files()) > 0 {
+		return c.send(ctx, req)
+	}
+
+	key, err := flightKey(ctx, req)
+	if err != nil {
+		return c.send(ctx, req)
+	}
+
+	// The shared request must outlive any one caller giving up, so it runs
+	// detached from their cancellation. Each caller decodes its own copy of
+	// the response, leaving it free to modify what it gets back.
+	detached := context.WithoutCancel(ctx)
+	ch := c.inflight.DoChan(key, func() (any, error) {
+		var resp Query
+		err := c.client.Run(detached, req, &rawQuery{&resp})
+		return resp.raw, err
+	})
+
+	select {
+	case <-ctx.Done():
+		return Query{}, ctx.Err()
+	case res := <-ch:
+		return c.decodeShared(res.Val.(json.RawMessage), res.Err)
+	}
+}
+
+func (c *Client) decodeShared(raw json.RawMessage, err error) (Query, error) {
+	var resp Query
+	if len(raw) == 0 {
+		return resp, err
+	}
+	if decodeErr := json.Unmarshal(raw, &resp); decodeErr != nil && err == nil {
+		err = decodeErr
+	}
+	if c.retainRaw {
+		resp.raw = append(json.RawMessage(nil), raw...)
+	}
+	return resp, err
+}
+
+// send sends req, keeping the raw response data when the client was created
+// with RetainRawResponses.
+func (c *Client) send(ctx context.Context, req *graphql.Request) (Query, error) {
+	var resp Query
+	if c.retainRaw {
+		err := c.client.Run(ctx, req, &rawQuery{&resp})
+		return resp, err
+	}
+	err := c.client.Run(ctx, req, &resp)
+	return resp, err
+}
+
+// flightKey identifies a query by its action, text, variables and the
+// credentials it's sent with.
+func flightKey(ctx context.Context, req *graphql.Request) (string, error) {
+	vars, err := json.Marshal(req.Vars())
+	if err != nil {
+		return "", err
+	}
+
+	auth, _ := ctx.Value(contextKeyAuthorization).(string)
+	return actionFromCtx(ctx) + "\x00" + auth + "\x00" + req.Query() + "\x00" + string(vars), nil
+}
@@ -0,0 +1,65 @@
+package fly
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDeduplicatedQueries(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data":{"app":{"id":"1","name":"web","ipAddresses":{"nodes":[{"id":"ip1"},{"id":"ip2"}]}}}}`)
+	}))
+	defer srv.Close()
+
+	c := NewClientFromOptions(ClientOptions{BaseURL: srv.URL, DeduplicateQueries: true})
+	query := `query { app(name: "web") { id name ipAddresses { nodes { id } } } }`
+
+	type result struct {
+		resp Query
+		err  error
+	}
+	run := func(ctx context.Context) chan result {
+		ch := make(chan result, 1)
+		go func() {
+			resp, err := c.RunWithContext(ctx, c.NewRequest(query))
+			ch <- result{resp, err}
+		}()
+		return ch
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := run(ctx)
+	first := run(context.Background())
+	second := run(context.Background())
+	time.Sleep(50 * time.Millisecond)
+
+	cancel()
+	if res := <-canceled; !errors.Is(res.err, context.Canceled) {
+		t.Errorf("canceled caller, got '%v', want '%v'", res.err, context.Canceled)
+	}
+
+	close(release)
+	a, b := <-first, <-second
+	if a.err != nil || b.err != nil {
+		t.Fatalf("got '%v' and '%v', want no errors", a.err, b.err)
+	}
+
+	a.resp.App.IPAddresses.Nodes[0].ID = "changed"
+	if got := b.resp.App.IPAddresses.Nodes[0].ID; got != "ip1" {
+		t.Errorf("shared response, got '%v', want '%v'", got, "ip1")
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests, got '%v', want '%v'", got, 1)
+	}
+}
@@ -0,0 +1,153 @@
+package fly
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// ParseSSHCertificate parses a certificate in authorized_keys format, as
+// returned by EstablishSSHKey and IssueSSHCertificate.
+func ParseSSHCertificate(s string) (*ssh.Certificate, error) {
+	pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(s))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ssh certificate: %w", err)
+	}
+
+	cert, ok := pub.(*ssh.Certificate)
+	if !ok {
+		return nil, fmt.Errorf("expected an ssh certificate, got a %s key", pub.Type())
+	}
+	return cert, nil
+}
+
+// SSHCertificateValidity returns the window in which cert is valid.
+func SSHCertificateValidity(cert *ssh.Certificate) (validAfter, validBefore time.Time) {
+	validAfter = time.Unix(int64(cert.ValidAfter), 0)
+	if cert.ValidBefore == ssh.CertTimeInfinity {
+		return validAfter, time.Time{}
+	}
+	return validAfter, time.Unix(int64(cert.ValidBefore), 0)
+}
+
+func (c *SSHCertificate) Parse() (*ssh.Certificate, error) {
+	return ParseSSHCertificate(c.Certificate)
+}
+
+func (c *IssuedCertificate) Parse() (*ssh.Certificate, error) {
+	return ParseSSHCertificate(c.Certificate)
+}
+
+// ExpiresAt returns when the certificate stops being valid, or the zero time
+// if it never expires.
+func (c *IssuedCertificate) ExpiresAt() (time.Time, error) {
+	cert, err := c.Parse()
+	if err != nil {
+		return time.Time{}, err
+	}
+	_, validBefore := SSHCertificateValidity(cert)
+	return validBefore, nil
+}
+
+const sshCertificateRetryInterval = time.Minute
+
+// SSHCertificateRenewer keeps an SSH certificate current by reissuing it in
+// the background shortly before it expires.
+type SSHCertificateRenewer struct {
+	issue       func(ctx context.Context) (*IssuedCertificate, error)
+	renewBefore time.Duration
+	onError     func(error)
+
+	mu      sync.RWMutex
+	current *IssuedCertificate
+}
+
+// NewSSHCertificateRenewer issues a certificate with issue, then reissues it
+// renewBefore its expiry until ctx is done. renewBefore must be shorter than
+// the certificate's validity, and reissues are at least a minute apart.
+// onError, if not nil, is called when a background reissue fails; the
+// renewer retries a minute later.
+func NewSSHCertificateRenewer(ctx context.Context, issue func(ctx context.Context) (*IssuedCertificate, error), renewBefore time.Duration, onError func(error)) (*SSHCertificateRenewer, error) {
+	r := &SSHCertificateRenewer{
+		issue:       issue,
+		renewBefore: renewBefore,
+		onError:     onError,
+	}
+
+	expiresAt, err := r.renew(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !expiresAt.IsZero() {
+		cert, err := r.Current().Parse()
+		if err != nil {
+			return nil, err
+		}
+		validAfter, validBefore := SSHCertificateValidity(cert)
+		if validity := validBefore.Sub(validAfter); renewBefore >= validity {
+			return nil, fmt.Errorf("renewBefore %s must be shorter than the certificate's validity of %s", renewBefore, validity)
+		}
+	}
+
+	go r.run(ctx, expiresAt)
+	return r, nil
+}
+
+// Current returns the most recently issued certificate.
+func (r *SSHCertificateRenewer) Current() *IssuedCertificate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.current
+}
+
+func (r *SSHCertificateRenewer) renew(ctx context.Context) (time.Time, error) {
+	issued, err := r.issue(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	expiresAt, err := issued.ExpiresAt()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	r.mu.Lock()
+	r.current = issued
+	r.mu.Unlock()
+
+	return expiresAt, nil
+}
+
+func (r *SSHCertificateRenewer) run(ctx context.Context, expiresAt time.Time) {
+	for {
+		if expiresAt.IsZero() {
+			return
+		}
+
+		// A certificate issued with less validity left than renewBefore
+		// would otherwise be reissued straight away, over and over.
+		delay := max(time.Until(expiresAt.Add(-r.renewBefore)), sshCertificateRetryInterval)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		next, err := r.renew(ctx)
+		if err != nil {
+			if r.onError != nil {
+				r.onError(err)
+			}
+			next = time.Now().Add(r.renewBefore + sshCertificateRetryInterval)
+		}
+		expiresAt = next
+	}
+}
@@ -0,0 +1,75 @@
+package fly
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func issueTestCertificate(t *testing.T, validAfter, validBefore time.Time) *IssuedCertificate {
+	t.Helper()
+
+	_, caKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ssh.NewPublicKey(userKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert := &ssh.Certificate{
+		Key:         pub,
+		CertType:    ssh.UserCert,
+		ValidAfter:  uint64(validAfter.Unix()),
+		ValidBefore: uint64(validBefore.Unix()),
+	}
+	if err := cert.SignCert(rand.Reader, signer); err != nil {
+		t.Fatal(err)
+	}
+	return &IssuedCertificate{Certificate: string(ssh.MarshalAuthorizedKey(cert))}
+}
+
+func TestSSHCertificateRenewer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	now := time.Now()
+	var issued atomic.Int32
+	issue := func(context.Context) (*IssuedCertificate, error) {
+		issued.Add(1)
+		// Issued late into its validity, already inside the renewal window.
+		return issueTestCertificate(t, now.Add(-50*time.Minute), now.Add(10*time.Minute)), nil
+	}
+
+	if _, err := NewSSHCertificateRenewer(ctx, issue, time.Hour, nil); err == nil {
+		t.Error("renewBefore longer than the validity, got nil, want an error")
+	}
+
+	issued.Store(0)
+	r, err := NewSSHCertificateRenewer(ctx, issue, 30*time.Minute, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Current() == nil {
+		t.Fatal("got no certificate")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if got := issued.Load(); got != 1 {
+		t.Errorf("issued, got '%v', want '%v'", got, 1)
+	}
+}
@@ -0,0 +1,97 @@
+// Package sshagent hands SSH certificates issued by the API to ssh-agent, or
+// to an in-memory agent, so they can be used as ready-made identities.
+package sshagent
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	fly "github.com/superfly/fly-go"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// Dial connects to the agent listening on SSH_AUTH_SOCK. The caller should
+// close the returned connection once done with the agent.
+func Dial() (agent.ExtendedAgent, net.Conn, error) {
+	sock := os.Getenv("SSH_AUTH_SOCK")
+	if sock == "" {
+		return nil, nil, errors.New("SSH_AUTH_SOCK is not set, is ssh-agent running?")
+	}
+
+	conn, err := net.Dial("unix", sock)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to ssh-agent: %w", err)
+	}
+	return agent.NewClient(conn), conn, nil
+}
+
+// Add adds an issued certificate and the private key issued along with it to
+// a. The agent drops the identity when the certificate expires.
+func Add(a agent.Agent, issued *fly.IssuedCertificate, comment string) error {
+	if issued.Key == "" {
+		return errors.New("certificate was issued for a provided public key, use AddWithKey")
+	}
+
+	key, err := ssh.ParseRawPrivateKey([]byte(issued.Key))
+	if err != nil {
+		return fmt.Errorf("failed to parse issued private key: %w", err)
+	}
+	return AddWithKey(a, issued, key, comment)
+}
+
+// AddWithKey adds an issued certificate to a, using key, the private half of
+// the public key the certificate was issued for.
+func AddWithKey(a agent.Agent, issued *fly.IssuedCertificate, key crypto.PrivateKey, comment string) error {
+	cert, err := issued.Parse()
+	if err != nil {
+		return err
+	}
+
+	added := agent.AddedKey{
+		PrivateKey:  key,
+		Certificate: cert,
+		Comment:     comment,
+	}
+	if _, validBefore := fly.SSHCertificateValidity(cert); !validBefore.IsZero() {
+		remaining := time.Until(validBefore)
+		if remaining <= 0 {
+			return errors.New("certificate has already expired")
+		}
+		added.LifetimeSecs = uint32(remaining.Seconds())
+	}
+
+	if err := a.Add(added); err != nil {
+		return fmt.Errorf("failed to add certificate to agent: %w", err)
+	}
+	return nil
+}
+
+// NewKeyring returns an in-memory agent holding the issued certificate.
+func NewKeyring(issued *fly.IssuedCertificate, comment string) (agent.Agent, error) {
+	keyring := agent.NewKeyring()
+	if err := Add(keyring, issued, comment); err != nil {
+		return nil, err
+	}
+	return keyring, nil
+}
+
+// Signer returns an ssh.Signer authenticating with the issued certificate,
+// for use in an ssh.ClientConfig.
+func Signer(issued *fly.IssuedCertificate) (ssh.Signer, error) {
+	cert, err := issued.Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	keySigner, err := ssh.ParsePrivateKey([]byte(issued.Key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse issued private key: %w", err)
+	}
+
+	return ssh.NewCertSigner(cert, keySigner)
+}
@@ -1,33 +1,44 @@
 package fly
 
 import (
+	"encoding/json"
 	"fmt"
+	"net"
 	"time"
 )
 
+// PageInfo describes where a page of a connection falls in the full list.
+type PageInfo struct {
+	HasNextPage bool
+	EndCursor   string
+}
+
+// Connection is a page of a paginated GraphQL list. PageInfo and TotalCount
+// are only set when the query selects them.
+type Connection[T any] struct {
+	Nodes      []T
+	PageInfo   PageInfo
+	TotalCount int
+}
+
 // Query - Master query which encapsulates all possible returned structures
 type Query struct {
 	Errors Errors
 
-	Apps struct {
-		PageInfo struct {
-			HasNextPage bool
-			EndCursor   string
-		}
-		Nodes []App
-	}
+	raw json.RawMessage
+
+	Apps            Connection[App]
 	App             App
 	AppCompact      AppCompact
 	AppBasic        AppBasic
 	AppCertsCompact AppCertsCompact
 	Viewer          User
 	GqlMachine      GqlMachine
-	Organizations   struct {
-		Nodes []Organization
-	}
+	Organizations   Connection[Organization]
 
 	Organization        *Organization
 	OrganizationDetails OrganizationDetails
+	OrganizationBilling *OrganizationBilling
 	Volume              struct {
 		App struct {
 			Name string
@@ -54,6 +65,16 @@ type Query struct {
 		App App
 	}
 
+	CreateTurbokuApp struct {
+		App App
+	}
+
+	CreateBuild *Build
+
+	SetAppDeletionProtection struct {
+		App App
+	}
+
 	SetSecrets struct {
 		Release Release
 	}
@@ -113,6 +134,16 @@ type Query struct {
 		Warnings []ImportDnsWarning
 		Changes  []ImportDnsChange
 	}
+
+	CreateDnsRecord struct {
+		Record *DNSRecord
+	}
+	UpdateDnsRecord struct {
+		Record *DNSRecord
+	}
+	UpdateDnsRecords struct {
+		Changes []DNSRecordDiff
+	}
 	CreateOrganization CreateOrganizationPayload
 	DeleteOrganization DeleteOrganizationPayload
 
@@ -126,6 +157,19 @@ type Query struct {
 		Organization Organization
 	}
 
+	SetSlackHandler struct {
+		Handler *HealthCheckHandler
+	}
+	SetPagerdutyHandler struct {
+		Handler *HealthCheckHandler
+	}
+
+	AddOn       *AddOn
+	CreateAddOn struct {
+		AddOn *AddOn
+	}
+
+	CreatePostgresCluster *CreatePostgresClusterPayload
 	AttachPostgresCluster *AttachPostgresClusterPayload
 	EnablePostgresConsul  *PostgresEnableConsulPayload
 
@@ -135,17 +179,73 @@ type Query struct {
 		InvalidPeerIPs []string
 	}
 
-	PostgresAttachments struct {
-		Nodes []*PostgresClusterAttachment
-	}
+	PostgresAttachments Connection[*PostgresClusterAttachment]
 
 	DeleteOrganizationMembership *DeleteOrganizationMembershipPayload
 
+	UpdateOrganizationSettings struct {
+		Organization Organization
+	}
+
+	UpdateOrganizationMembership struct {
+		Membership OrganizationMembership
+	}
+
 	UpdateRemoteBuilder struct {
 		Organization Organization
 	}
 
 	CanPerformBluegreenDeployment bool
+
+	UpdateServices struct {
+		App App
+	}
+}
+
+// Raw returns the response's data as sent by the API, or nil unless the
+// client was created with ClientOptions.RetainRawResponses.
+func (q Query) Raw() json.RawMessage { return q.raw }
+
+// rawQuery decodes a response into Query while keeping a copy of its data.
+type rawQuery struct{ *Query }
+
+func (r *rawQuery) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, r.Query); err != nil {
+		return err
+	}
+	r.Query.raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+type AddWireGuardPeerInput struct {
+	OrganizationID string  `json:"organizationId"`
+	Name           string  `json:"name"`
+	Pubkey         string  `json:"pubkey"`
+	Region         *string `json:"region,omitempty"`
+	Nats           bool    `json:"nats"`
+	Network        *string `json:"network,omitempty"`
+}
+
+type RemoveWireGuardPeerInput struct {
+	OrganizationID string `json:"organizationId"`
+	Name           string `json:"name"`
+}
+
+type CreateDelegatedWireGuardTokenInput struct {
+	OrganizationID string `json:"organizationId"`
+	Name           string `json:"name,omitempty"`
+}
+
+// DeleteDelegatedWireGuardTokenInput identifies the token to delete by
+// either Name or Token.
+type DeleteDelegatedWireGuardTokenInput struct {
+	OrganizationID string  `json:"organizationId"`
+	Name           *string `json:"name,omitempty"`
+	Token          *string `json:"token,omitempty"`
+}
+
+type ValidateWireGuardPeersInput struct {
+	PeerIPs []string `json:"peerIps"`
 }
 
 type CreatedWireGuardPeer struct {
@@ -165,12 +265,27 @@ type DelegatedWireGuardToken struct {
 
 type DelegatedWireGuardTokenHandle /* whatever */ struct {
 	Name string
+
+	// CreatedAt, LastUsedAt and CreatedBy are only set by
+	// GetDelegatedWireGuardTokenDetails.
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	CreatedBy  *User
 }
 
 type SSHCertificate struct {
 	Certificate string
 }
 
+type IssueCertificateInput struct {
+	OrganizationID string                    `json:"organizationId"`
+	Principals     []string                  `json:"principals"`
+	AppNames       []string                  `json:"appNames"`
+	PublicKey      string                    `json:"publicKey"`
+	ValidHours     *int                      `json:"validHours,omitempty"`
+	Extensions     []SSHCertificateExtension `json:"extensions,omitempty"`
+}
+
 type IssuedCertificate struct {
 	Certificate string
 	Key         string
@@ -219,27 +334,26 @@ type App struct {
 	AppURL    string
 	Version   int
 	NetworkID int
-
-	Release        *Release
-	Organization   Organization
-	Secrets        []Secret
-	CurrentRelease *Release
-	Releases       struct {
-		Nodes []Release
-	}
-	IPAddresses struct {
-		Nodes []IPAddress
-	}
+	// Network is the name of the custom private network the app is attached
+	// to, empty for the organization's default network.
+	Network string
+	// DeletionProtection prevents the app from being deleted until it's
+	// turned off. Only queried by GetAppDeletionProtection.
+	DeletionProtection bool
+
+	Release         *Release
+	Organization    Organization
+	Secrets         []Secret
+	CurrentRelease  *Release
+	Releases        Connection[Release]
+	Builds          Connection[Build]
+	IPAddresses     Connection[IPAddress]
 	SharedIPAddress string
 	IPAddress       *IPAddress
-	Certificates    struct {
-		Nodes []AppCertificate
-	}
+	Certificates    Connection[AppCertificate]
 	Certificate     AppCertificate
-	PostgresAppRole *struct {
-		Name string
-	}
-	Image *Image
+	PostgresAppRole *PostgresClusterAppRole
+	Image           *Image
 
 	ImageUpgradeAvailable       bool
 	ImageVersionTrackingEnabled bool
@@ -247,27 +361,49 @@ type App struct {
 	LatestImageDetails          ImageVersion
 
 	PlatformVersion     string
-	LimitedAccessTokens *struct {
-		Nodes []LimitedAccessToken
-	}
+	LimitedAccessTokens *Connection[LimitedAccessToken]
+	Volumes             Connection[GqlVolume]
+	Services            []Service
+	HealthChecks        Connection[CheckState]
+	Changes             Connection[AppEvent]
+	HostIssues          Connection[HostIssue]
 }
 type LimitedAccessToken struct {
 	Id        string
 	Name      string
 	ExpiresAt time.Time
 	User      User
+
+	// Profile, CreatedAt and LastUsedAt are only set by
+	// GetOrganizationLimitedAccessTokens.
+	Profile    string
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+}
+
+// Expired reports whether the token has expired as of now.
+func (t *LimitedAccessToken) Expired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && !t.ExpiresAt.After(now)
 }
 
 type AppCertsCompact struct {
-	Certificates struct {
-		Nodes []AppCertificateCompact
-	}
+	Certificates Connection[AppCertificateCompact]
 }
 
 type AppCertificateCompact struct {
-	CreatedAt    time.Time
-	Hostname     string
-	ClientStatus string
+	ID                    string
+	CreatedAt             time.Time
+	Hostname              string
+	ClientStatus          string
+	Configured            bool
+	DNSValidationHostname string
+	DNSValidationTarget   string
+	Issued                struct {
+		Nodes []struct {
+			ExpiresAt time.Time
+			Type      string
+		}
+	}
 }
 
 type AppCompact struct {
@@ -282,6 +418,7 @@ type AppCompact struct {
 	PostgresAppRole *struct {
 		Name string
 	}
+	Volumes Connection[GqlVolume]
 }
 
 func (app *AppCompact) IsPostgresApp() bool {
@@ -298,9 +435,7 @@ type AppInfo struct {
 	Version         int
 	PlatformVersion string
 	Organization    *OrganizationBasic
-	IPAddresses     struct {
-		Nodes []IPAddress
-	}
+	IPAddresses     Connection[IPAddress]
 }
 
 type AppBasic struct {
@@ -321,7 +456,20 @@ type Organization struct {
 	Type               string
 	PaidPlan           bool
 	Billable           bool
-	Settings           map[string]any
+	BillingStatus      BillingStatus
+	// CreditBalance is the organization's remaining credit, in cents.
+	CreditBalance          int
+	CreditBalanceFormatted string
+	IsCreditCardSaved      bool
+	ViewerRole             string
+	Settings               map[string]any
+	FeatureFlags           []FeatureFlag
+
+	AddOns Connection[AddOn]
+
+	HealthCheckHandlers Connection[HealthCheckHandler]
+
+	Apps Connection[App]
 
 	Domains struct {
 		Nodes *[]*Domain
@@ -349,13 +497,9 @@ type Organization struct {
 		}
 	}
 
-	LoggedCertificates *struct {
-		Nodes []LoggedCertificate
-	}
+	LoggedCertificates *Connection[LoggedCertificate]
 
-	LimitedAccessTokens *struct {
-		Nodes []LimitedAccessToken
-	}
+	LimitedAccessTokens *Connection[LimitedAccessToken]
 }
 
 func (o *Organization) GetID() string {
@@ -366,6 +510,72 @@ func (o *Organization) GetSlug() string {
 	return o.Slug
 }
 
+// DomainNodes returns the organization's domains, or nil if they weren't
+// part of the response.
+func (o *Organization) DomainNodes() []*Domain {
+	if o == nil || o.Domains.Nodes == nil {
+		return nil
+	}
+	return *o.Domains.Nodes
+}
+
+// WireGuardPeerNodes returns the organization's peers, or nil if they
+// weren't part of the response.
+func (o *Organization) WireGuardPeerNodes() []*WireGuardPeer {
+	if o == nil || o.WireGuardPeers.Nodes == nil {
+		return nil
+	}
+	return *o.WireGuardPeers.Nodes
+}
+
+// DelegatedWireGuardTokenNodes returns the organization's delegated tokens,
+// or nil if they weren't part of the response.
+func (o *Organization) DelegatedWireGuardTokenNodes() []*DelegatedWireGuardTokenHandle {
+	if o == nil || o.DelegatedWireGuardTokens.Nodes == nil {
+		return nil
+	}
+	return *o.DelegatedWireGuardTokens.Nodes
+}
+
+// LoggedCertificateNodes returns the organization's logged SSH
+// certificates, or nil if they weren't part of the response.
+func (o *Organization) LoggedCertificateNodes() []LoggedCertificate {
+	if o == nil || o.LoggedCertificates == nil {
+		return nil
+	}
+	return o.LoggedCertificates.Nodes
+}
+
+type BillingStatus string
+
+const (
+	BillingStatusCurrent        BillingStatus = "CURRENT"
+	BillingStatusDelinquent     BillingStatus = "DELINQUENT"
+	BillingStatusPastDue        BillingStatus = "PAST_DUE"
+	BillingStatusSourceRequired BillingStatus = "SOURCE_REQUIRED"
+	BillingStatusTrialActive    BillingStatus = "TRIAL_ACTIVE"
+	BillingStatusTrialEnded     BillingStatus = "TRIAL_ENDED"
+)
+
+type FeatureFlag struct {
+	Name    string
+	Enabled bool
+}
+
+const (
+	// FeatureFlagMachinesOnly is enabled for organizations that can only
+	// create apps on the machines platform.
+	FeatureFlagMachinesOnly = "machines_only"
+)
+
+// FeatureFlags maps feature flag names to whether they're enabled.
+type FeatureFlags map[string]bool
+
+// Enabled reports whether the flag is enabled. Unknown flags are disabled.
+func (f FeatureFlags) Enabled(name string) bool {
+	return f[name]
+}
+
 type OrganizationBasic struct {
 	ID       string
 	Name     string
@@ -396,10 +606,8 @@ type OrganizationDetails struct {
 	Slug               string
 	Type               string
 	ViewerRole         string
-	Apps               struct {
-		Nodes []App
-	}
-	Members struct {
+	Apps               Connection[App]
+	Members            struct {
 		Edges []OrganizationMembershipEdge
 	}
 }
@@ -411,6 +619,38 @@ type OrganizationMembershipEdge struct {
 	JoinedAt time.Time
 }
 
+type OrganizationMembership struct {
+	Role     OrganizationMemberRole
+	JoinedAt time.Time
+	User     User
+}
+
+type OrganizationBilling struct {
+	ID            string
+	Slug          string
+	PaidPlan      bool
+	Billable      bool
+	BillingStatus BillingStatus
+	// CreditBalance is the organization's remaining credit, in cents.
+	CreditBalance          int
+	CreditBalanceFormatted string
+	IsCreditCardSaved      bool
+	Invoices               Connection[Invoice]
+}
+
+type Invoice struct {
+	ID     string
+	Status string
+	// Total is the amount due, in cents of Currency.
+	Total          int
+	TotalFormatted string
+	Currency       string
+	PeriodStart    time.Time
+	PeriodEnd      time.Time
+	DueDate        *time.Time
+	URL            string
+}
+
 type Billable struct {
 	Category string
 	Product  string
@@ -434,11 +674,39 @@ type DNSRecords struct {
 }
 
 type IPAddress struct {
-	ID        string
-	Address   string
-	Type      string
-	Region    string
-	CreatedAt time.Time
+	ID          string
+	Address     string
+	Type        string
+	Region      string
+	CreatedAt   time.Time
+	ServiceName string
+	Network     *struct {
+		Name string
+	}
+}
+
+// IP parses the address, returning nil if it is not a valid IP.
+func (ip *IPAddress) IP() net.IP {
+	return net.ParseIP(ip.Address)
+}
+
+func (ip *IPAddress) IsPrivate() bool {
+	return ip.Type == IPAddressTypePrivateV6
+}
+
+func (ip *IPAddress) IsShared() bool {
+	return ip.Type == IPAddressTypeSharedV4
+}
+
+// IsFlycast reports whether this is a Flycast address: a private address
+// routed through the proxy, reachable only from the private network.
+func (ip *IPAddress) IsFlycast() bool {
+	return ip.IsPrivate()
+}
+
+// IsPublic reports whether the address is reachable from the internet.
+func (ip *IPAddress) IsPublic() bool {
+	return !ip.IsPrivate()
 }
 
 type VMSize struct {
@@ -480,12 +748,52 @@ type UnsetSecretsInput struct {
 }
 
 type CreateAppInput struct {
+	OrganizationID string `json:"organizationId"`
+	// OrganizationSlug is resolved to OrganizationID when that's empty.
+	OrganizationSlug string  `json:"-"`
+	Name             string  `json:"name"`
+	PreferredRegion  *string `json:"preferredRegion,omitempty"`
+	Network          *string `json:"network,omitempty"`
+	AppRoleID        string  `json:"appRoleId,omitempty"`
+	Machines         bool    `json:"machines"`
+}
+
+type CreateTurbokuAppInput struct {
 	OrganizationID  string  `json:"organizationId"`
-	Name            string  `json:"name"`
+	Name            string  `json:"name,omitempty"`
+	HerokuAppName   string  `json:"herokuAppName"`
+	HerokuToken     string  `json:"herokuToken"`
 	PreferredRegion *string `json:"preferredRegion,omitempty"`
-	Network         *string `json:"network,omitempty"`
-	AppRoleID       string  `json:"appRoleId,omitempty"`
-	Machines        bool    `json:"machines"`
+}
+
+type CreateBuildInput struct {
+	AppName             string            `json:"appName"`
+	MachineID           string            `json:"machineId,omitempty"`
+	BuilderType         string            `json:"builderType"`
+	ImageOpts           BuildImageOptions `json:"imageOpts"`
+	StrategiesAvailable []string          `json:"strategiesAvailable"`
+}
+
+type BuildImageOptions struct {
+	DockerfilePath string            `json:"dockerfilePath,omitempty"`
+	ImageRef       string            `json:"imageRef,omitempty"`
+	ImageLabel     string            `json:"imageLabel,omitempty"`
+	BuildArgs      map[string]string `json:"buildArgs,omitempty"`
+
+	// NoCache builds every layer from scratch instead of reusing the
+	// builder's cache.
+	NoCache bool `json:"noCache,omitempty"`
+	// CacheFrom lists images whose layers may be reused as cache.
+	CacheFrom []string `json:"cacheFrom,omitempty"`
+	// Target is the Dockerfile stage to build.
+	Target string `json:"target,omitempty"`
+
+	// Builder is the buildpack builder image, such as
+	// "heroku/builder:24". Setting it builds with buildpacks instead of a
+	// Dockerfile.
+	Builder string `json:"builder,omitempty"`
+	// Buildpacks overrides the builder's default buildpacks.
+	Buildpacks []string `json:"buildPacks,omitempty"`
 }
 
 type LogEntry struct {
@@ -520,6 +828,80 @@ type LogEntry struct {
 	}
 }
 
+// Time returns Timestamp parsed, or the zero time if it's malformed.
+// Timestamp keeps the value as sent, since its precision varies by source.
+func (e *LogEntry) Time() time.Time {
+	t, err := time.Parse(time.RFC3339Nano, e.Timestamp)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// AppEvent is a change to an app. Reason is the kind of change, for example
+// "deploy", "scale", "restart" or "secrets".
+type AppEvent struct {
+	ID          string
+	Description string
+	Reason      string
+	Status      string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	User        *User
+}
+
+type CheckState struct {
+	Name        string
+	Status      string
+	Output      string
+	ServiceName string
+	UpdatedAt   time.Time
+	Allocation  *struct {
+		ID     string
+		Region string
+	}
+}
+
+type HealthCheckSummary struct {
+	Name     string
+	Passing  int
+	Warning  int
+	Critical int
+	States   []CheckState
+}
+
+// AllPassing reports whether every instance of the check is passing.
+func (s *HealthCheckSummary) AllPassing() bool {
+	return s.Passing == len(s.States)
+}
+
+type HealthCheckHandler struct {
+	Name string
+	Type string
+}
+
+type SetSlackHandlerInput struct {
+	OrganizationID  string  `json:"organizationId"`
+	Name            string  `json:"name"`
+	SlackWebhookURL string  `json:"slackWebhookUrl"`
+	SlackChannel    *string `json:"slackChannel,omitempty"`
+	SlackUsername   *string `json:"slackUsername,omitempty"`
+	SlackIconURL    *string `json:"slackIconUrl,omitempty"`
+}
+
+type SetPagerdutyHandlerInput struct {
+	OrganizationID string `json:"organizationId"`
+	Name           string `json:"name"`
+	PagerdutyToken string `json:"pagerdutyToken"`
+	// PagerdutyStatusMap maps check statuses to PagerDuty severities.
+	PagerdutyStatusMap map[string]string `json:"pagerdutyStatusMap,omitempty"`
+}
+
+type DeleteHealthCheckHandlerInput struct {
+	OrganizationID string `json:"organizationId"`
+	Name           string `json:"name"`
+}
+
 type Region struct {
 	Code             string
 	Name             string
@@ -527,6 +909,18 @@ type Region struct {
 	Longitude        float32
 	GatewayAvailable bool
 	RequiresPaidPlan bool
+	VolumesAvailable bool
+	GPUAvailable     bool
+	// Paused regions don't accept new machines.
+	Paused bool
+	// CapacityConstrained regions may reject machines needing large or
+	// dedicated guests.
+	CapacityConstrained bool
+}
+
+// AcceptsMachines reports whether new machines can be placed in the region.
+func (r *Region) AcceptsMachines() bool {
+	return !r.Paused
 }
 
 type Release struct {
@@ -544,6 +938,22 @@ type Release struct {
 	ImageRef           string
 }
 
+// Build is a record of an image build for an app, whether it ran on a
+// remote builder or locally.
+type Build struct {
+	ID         string
+	Status     string
+	InProgress bool
+	Image      string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+
+	// Builder and Buildpacks are set for buildpack builds, to the builder
+	// image and buildpacks the build actually ran with.
+	Builder    string
+	Buildpacks []string
+}
+
 type SignedUrl struct {
 	PutUrl string
 }
@@ -572,6 +982,18 @@ type AppCertificate struct {
 	}
 }
 
+// ExpiresAt returns the earliest expiry of the certificate's issued
+// certificates, or the zero time if none have been issued yet.
+func (c *AppCertificate) ExpiresAt() time.Time {
+	var earliest time.Time
+	for _, issued := range c.Issued.Nodes {
+		if earliest.IsZero() || issued.ExpiresAt.Before(earliest) {
+			earliest = issued.ExpiresAt
+		}
+	}
+	return earliest
+}
+
 type CreateOrganizationPayload struct {
 	Organization Organization
 }
@@ -595,6 +1017,25 @@ type DeleteCertificatePayload struct {
 	Certificate AppCertificate
 }
 
+type SetAppDeletionProtectionInput struct {
+	AppID   string `json:"appId"`
+	Enabled bool   `json:"enabled"`
+}
+
+type MoveAppInput struct {
+	AppID          string `json:"appId"`
+	OrganizationID string `json:"organizationId"`
+}
+
+type CheckCertificateInput struct {
+	AppID    string `json:"appId"`
+	Hostname string `json:"hostname"`
+}
+
+type DeleteLimitedAccessTokenInput struct {
+	ID string `json:"id"`
+}
+
 type AllocateIPAddressInput struct {
 	AppID          string `json:"appId"`
 	Type           string `json:"type"`
@@ -604,9 +1045,58 @@ type AllocateIPAddressInput struct {
 }
 
 type ReleaseIPAddressInput struct {
-	AppID       *string `json:"appId"`
-	IPAddressID *string `json:"ipAddressId"`
-	IP          *string `json:"ip"`
+	AppID       *string `json:"appId,omitempty"`
+	IPAddressID *string `json:"ipAddressId,omitempty"`
+	IP          *string `json:"ip,omitempty"`
+}
+
+type Service struct {
+	Description  string
+	Protocol     string
+	InternalPort int
+	Ports        []PortHandler
+}
+
+type PortHandler struct {
+	Port     int
+	Handlers []string
+}
+
+type UpdateServicesInput struct {
+	AppID    string         `json:"appId"`
+	Services []ServiceInput `json:"services"`
+}
+
+type ServiceInput struct {
+	Protocol     string                   `json:"protocol"`
+	InternalPort int                      `json:"internalPort"`
+	Ports        []ServicePortInput       `json:"ports,omitempty"`
+	Concurrency  *ServiceConcurrencyInput `json:"concurrency,omitempty"`
+}
+
+type ServicePortInput struct {
+	Port       int                     `json:"port"`
+	Handlers   []string                `json:"handlers,omitempty"`
+	TLSOptions *ServiceTLSOptionsInput `json:"tlsOptions,omitempty"`
+}
+
+// ServiceTLSOptionsInput configures the tls handler of a port.
+type ServiceTLSOptionsInput struct {
+	ALPN     []string `json:"alpn,omitempty"`
+	Versions []string `json:"versions,omitempty"`
+}
+
+type ServiceConcurrencyInput struct {
+	Type      string `json:"type,omitempty"`
+	HardLimit int    `json:"hardLimit,omitempty"`
+	SoftLimit int    `json:"softLimit,omitempty"`
+}
+
+type HostIssue struct {
+	InternalID string
+	Message    string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
 }
 
 type Errors []Error
@@ -634,12 +1124,44 @@ type Domain struct {
 	ZoneNameservers      *[]string
 	DnsStatus            *string
 	RegistrationStatus   *string
-	ExpiresAt            time.Time
-	DnsRecords           *struct {
+	// Registrar is set for domains registered through Fly.io.
+	Registrar    *string
+	DnssecStatus *string
+	// SerialNumber is the SOA serial of the zone, bumped on every change.
+	SerialNumber int64
+	ExpiresAt    time.Time
+	UpdatedAt    time.Time
+	DnsRecords   *struct {
 		Nodes *[]*DNSRecord
 	}
 }
 
+// DNSRecordNodes returns the domain's records, or nil if they weren't part of
+// the response.
+func (d *Domain) DNSRecordNodes() []*DNSRecord {
+	if d == nil || d.DnsRecords == nil || d.DnsRecords.Nodes == nil {
+		return nil
+	}
+	return *d.DnsRecords.Nodes
+}
+
+type CreateDomainInput struct {
+	OrganizationID string `json:"organizationId"`
+	Name           string `json:"name"`
+}
+
+type CreateAndRegisterDomainInput struct {
+	OrganizationID string `json:"organizationId"`
+	Name           string `json:"name"`
+	AutoRenew      *bool  `json:"autoRenew,omitempty"`
+	// PrivateWhois hides the registrant's contact details from WHOIS.
+	PrivateWhois *bool `json:"privateWhois,omitempty"`
+}
+
+type CheckDomainInput struct {
+	DomainName string `json:"domainName"`
+}
+
 type CheckDomainResult struct {
 	DomainName            string
 	TLD                   string
@@ -665,6 +1187,75 @@ type DNSRecord struct {
 	UpdatedAt  time.Time
 }
 
+type ExportDNSZoneInput struct {
+	DomainID string `json:"domainId"`
+}
+
+type ImportDNSZoneInput struct {
+	DomainID string `json:"domainId"`
+	Zonefile string `json:"zonefile"`
+}
+
+type CreateDNSRecordInput struct {
+	DomainID string `json:"domainId"`
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	TTL      int    `json:"ttl"`
+	RData    string `json:"rdata"`
+}
+
+// UpdateDNSRecordInput changes the fields of a record that are set.
+type UpdateDNSRecordInput struct {
+	RecordID string  `json:"recordId"`
+	Name     *string `json:"name,omitempty"`
+	TTL      *int    `json:"ttl,omitempty"`
+	RData    *string `json:"rdata,omitempty"`
+}
+
+type DeleteDNSRecordInput struct {
+	RecordID string `json:"recordId"`
+}
+
+type DNSRecordChangeAction string
+
+const (
+	DNSRecordChangeCreate DNSRecordChangeAction = "CREATE"
+	DNSRecordChangeUpdate DNSRecordChangeAction = "UPDATE"
+	DNSRecordChangeDelete DNSRecordChangeAction = "DELETE"
+)
+
+// DNSRecordChangeInput is a single change in a ChangeDNSRecords batch.
+// RecordID identifies the record to update or delete and is left empty when
+// creating one.
+type DNSRecordChangeInput struct {
+	Action   DNSRecordChangeAction `json:"action"`
+	RecordID string                `json:"recordId,omitempty"`
+	Type     string                `json:"type,omitempty"`
+	Name     string                `json:"name,omitempty"`
+	TTL      int                   `json:"ttl,omitempty"`
+	RData    string                `json:"rdata,omitempty"`
+}
+
+type UpdateDNSRecordsInput struct {
+	DomainID string                 `json:"domainId"`
+	Changes  []DNSRecordChangeInput `json:"changes"`
+}
+
+type DNSRecordAttributes struct {
+	Name  string
+	Type  string
+	TTL   int
+	Rdata string
+}
+
+type DNSRecordDiff struct {
+	Type          DNSRecordChangeAction
+	OldText       string
+	NewText       string
+	OldAttributes *DNSRecordAttributes
+	NewAttributes *DNSRecordAttributes
+}
+
 type ImportDnsChange struct {
 	Action  string
 	OldText string
@@ -708,6 +1299,77 @@ type LoggedCertificate struct {
 	Cert string
 }
 
+type AddOn struct {
+	ID            string
+	Name          string
+	Status        string
+	ErrorMessage  string
+	PrimaryRegion string
+	ReadRegions   []string
+	Options       map[string]any
+	// PublicURL is the add-on's connection URL. For Redis, it resolves to
+	// a private address on the organization's network.
+	PublicURL string
+	PrivateIP string
+	// Environment holds the variables an app needs to use the add-on.
+	Environment map[string]any
+	SsoLink     string
+	AddOnPlan   *struct {
+		ID          string
+		Name        string
+		DisplayName string
+	}
+	Organization *OrganizationBasic
+}
+
+type CreateAddOnInput struct {
+	OrganizationID string `json:"organizationId"`
+	// OrganizationSlug is resolved to OrganizationID when that's empty.
+	OrganizationSlug string         `json:"-"`
+	Name             string         `json:"name,omitempty"`
+	PlanID           string         `json:"planId,omitempty"`
+	PrimaryRegion    string         `json:"primaryRegion,omitempty"`
+	ReadRegions      []string       `json:"readRegions,omitempty"`
+	Type             AddOnType      `json:"type"`
+	AppID            string         `json:"appId,omitempty"`
+	Options          map[string]any `json:"options,omitempty"`
+}
+
+type CreateRedisInput struct {
+	OrganizationID string   `json:"organizationId"`
+	Name           string   `json:"name,omitempty"`
+	PlanID         string   `json:"planId,omitempty"`
+	PrimaryRegion  string   `json:"primaryRegion,omitempty"`
+	ReadRegions    []string `json:"readRegions,omitempty"`
+	// Eviction evicts keys once the plan's memory limit is reached, instead
+	// of rejecting writes.
+	Eviction bool `json:"eviction"`
+}
+
+type DeleteAddOnInput struct {
+	Name string `json:"name"`
+}
+
+type CreatePostgresClusterInput struct {
+	OrganizationID string  `json:"organizationId"`
+	Name           string  `json:"name"`
+	Region         *string `json:"region,omitempty"`
+	Password       *string `json:"password,omitempty"`
+	VMSize         *string `json:"vmSize,omitempty"`
+	VolumeSizeGB   *int    `json:"volumeSizeGb,omitempty"`
+	// Count is the number of nodes in the cluster.
+	Count      *int    `json:"count,omitempty"`
+	ImageRef   *string `json:"imageRef,omitempty"`
+	SnapshotID *string `json:"snapshotId,omitempty"`
+}
+
+type CreatePostgresClusterPayload struct {
+	App              *App
+	Username         string
+	Password         string
+	ConnectionString string
+}
+
 type AttachPostgresClusterInput struct {
 	AppID                string  `json:"appId"`
 	PostgresClusterAppID string  `json:"postgresClusterAppId"`
@@ -735,8 +1397,38 @@ type PostgresEnableConsulPayload struct {
 }
 
 type EnsureRemoteBuilderInput struct {
-	AppName        *string `json:"appName"`
-	OrganizationID *string `json:"organizationId"`
+	AppName        *string `json:"appName,omitempty"`
+	OrganizationID *string `json:"organizationId,omitempty"`
+}
+
+type PostgresClusterAppRole struct {
+	Name      string
+	Databases []PostgresClusterDatabase
+	Users     []PostgresClusterUser
+}
+
+type PostgresClusterDatabase struct {
+	Name  string
+	Users []string
+}
+
+type PostgresClusterUser struct {
+	Username    string
+	IsSuperuser bool
+	Databases   []string
+}
+
+// ImageUpdate compares an app's deployed image with the latest image in the
+// repository it tracks.
+type ImageUpdate struct {
+	Current   ImageVersion
+	Latest    ImageVersion
+	Available bool
+}
+
+type PostgresImageUpdate struct {
+	ImageUpdate
+	Breaking bool
 }
 
 type PostgresClusterAttachment struct {
@@ -762,6 +1454,50 @@ type Invitation struct {
 	Organization *Organization
 }
 
+type CreateOrganizationInput struct {
+	Name string `json:"name"`
+}
+
+type DeleteOrganizationInput struct {
+	OrganizationID string `json:"organizationId"`
+}
+
+type CreateOrganizationInvitationInput struct {
+	OrganizationID string `json:"organizationId"`
+	Email          string `json:"email"`
+}
+
+type DeleteOrganizationMembershipInput struct {
+	OrganizationID string `json:"organizationId"`
+	UserID         string `json:"userId"`
+}
+
+// OrganizationSettings are the typed organization-wide settings stored in
+// Organization.Settings.
+type OrganizationSettings struct {
+	// EnforceSSO requires members to sign in through the organization's
+	// identity provider.
+	EnforceSSO bool `json:"enforce_sso"`
+	// TrustedAppSharing lets apps in other organizations trusted by this one
+	// reach its private network.
+	TrustedAppSharing bool   `json:"trusted_app_sharing"`
+	BillingEmail      string `json:"billing_email"`
+}
+
+// UpdateOrganizationSettingsInput changes the settings that are set.
+type UpdateOrganizationSettingsInput struct {
+	OrganizationID    string  `json:"organizationId"`
+	EnforceSSO        *bool   `json:"enforceSso,omitempty"`
+	TrustedAppSharing *bool   `json:"trustedAppSharing,omitempty"`
+	BillingEmail      *string `json:"billingEmail,omitempty"`
+}
+
+type UpdateOrganizationMembershipInput struct {
+	OrganizationID string                 `json:"organizationId"`
+	UserID         string                 `json:"userId"`
+	Role           OrganizationMemberRole `json:"role"`
+}
+
 type CreateOrganizationInvitation struct {
 	Invitation Invitation
 }
@@ -775,11 +1511,28 @@ type GqlMachine struct {
 
 	App *AppCompact
 
-	IPs struct {
-		Nodes []*MachineIP
+	IPs Connection[*MachineIP]
+}
+
+type GqlVolume struct {
+	ID                 string
+	InternalID         string
+	Name               string
+	State              string
+	SizeGb             int
+	Region             string
+	Encrypted          bool
+	CreatedAt          time.Time
+	AttachedMachine    *GqlMachine
+	AttachedAllocation *struct {
+		ID string
 	}
 }
 
+func (v *GqlVolume) IsAttached() bool {
+	return v.AttachedMachine != nil || v.AttachedAllocation != nil
+}
+
 type Logger interface {
 	Debug(v ...interface{})
 	Debugf(format string, v ...interface{})
@@ -0,0 +1,110 @@
+package fly
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestInputSerialization pins the JSON sent for mutation inputs to the field
+// names the API expects, so renamed or untagged fields don't silently drop.
+func TestInputSerialization(t *testing.T) {
+	type testcase struct {
+		name string
+		in   any
+		want string
+	}
+
+	region := "ord"
+	cases := []testcase{
+		{
+			name: "create app",
+			in:   CreateAppInput{OrganizationID: "org", Name: "app", PreferredRegion: &region, Machines: true},
+			want: `{"organizationId":"org","name":"app","preferredRegion":"ord","machines":true}`,
+		},
+		{
+			name: "move app",
+			in:   MoveAppInput{AppID: "app", OrganizationID: "org"},
+			want: `{"appId":"app","organizationId":"org"}`,
+		},
+		{
+			name: "set secrets",
+			in:   SetSecretsInput{AppID: "app", Secrets: []SetSecretsInputSecret{{Key: "K", Value: "V"}}},
+			want: `{"appId":"app","secrets":[{"key":"K","value":"V"}]}`,
+		},
+		{
+			name: "allocate ip",
+			in:   AllocateIPAddressInput{AppID: "app", Type: IPAddressTypeV6, Region: ""},
+			want: `{"appId":"app","type":"v6","region":""}`,
+		},
+		{
+			name: "release ip by id",
+			in:   ReleaseIPAddressInput{IPAddressID: &region},
+			want: `{"ipAddressId":"ord"}`,
+		},
+		{
+			name: "check certificate",
+			in:   CheckCertificateInput{AppID: "app", Hostname: "example.com"},
+			want: `{"appId":"app","hostname":"example.com"}`,
+		},
+		{
+			name: "add wireguard peer",
+			in:   AddWireGuardPeerInput{OrganizationID: "org", Name: "peer", Pubkey: "key"},
+			want: `{"organizationId":"org","name":"peer","pubkey":"key","nats":false}`,
+		},
+		{
+			name: "ensure remote builder",
+			in:   EnsureRemoteBuilderInput{AppName: &region},
+			want: `{"appName":"ord"}`,
+		},
+		{
+			name: "create build without cache",
+			in:   CreateBuildInput{AppName: "app", BuilderType: "remote", ImageOpts: BuildImageOptions{NoCache: true, Target: "prod"}},
+			want: `{"appName":"app","builderType":"remote","imageOpts":{"noCache":true,"target":"prod"},"strategiesAvailable":null}`,
+		},
+		{
+			name: "dns record change",
+			in:   DNSRecordChangeInput{Action: DNSRecordChangeDelete, RecordID: "rec"},
+			want: `{"action":"DELETE","recordId":"rec"}`,
+		},
+		{
+			name: "create redis",
+			in:   CreateRedisInput{OrganizationID: "org", Name: "cache", PlanID: "plan", PrimaryRegion: "ord", Eviction: true},
+			want: `{"organizationId":"org","name":"cache","planId":"plan","primaryRegion":"ord","eviction":true}`,
+		},
+	}
+
+	for _, tc := range cases {
+		b, err := json.Marshal(tc.in)
+		if err != nil {
+			t.Fatalf("%s, unexpected error: %v", tc.name, err)
+		}
+		if got := string(b); got != tc.want {
+			t.Errorf("%s, got '%v', want '%v'", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRawResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data":{"app":{"id":"1","name":"web","futureField":true}}}`)
+	}))
+	defer srv.Close()
+
+	c := NewClientFromOptions(ClientOptions{BaseURL: srv.URL, RetainRawResponses: true})
+	resp, err := c.RunWithContext(context.Background(), c.NewRequest(`query { app(name: "web") { id name futureField } }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.App.Name != "web" {
+		t.Errorf("got '%v', want '%v'", resp.App.Name, "web")
+	}
+	if got, want := string(resp.Raw()), `{"app":{"id":"1","name":"web","futureField":true}}`; got != want {
+		t.Errorf("got '%v', want '%v'", got, want)
+	}
+}
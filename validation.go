@@ -0,0 +1,149 @@
+package fly
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/superfly/graphql"
+)
+
+var (
+	appNamePattern    = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]*[a-z0-9])?$`)
+	orgSlugPattern    = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]*[a-z0-9])?$`)
+	regionCodePattern = regexp.MustCompile(`^[a-z]{3}$`)
+	secretKeyPattern  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+const maxAppNameLength = 63
+
+// ValidateAppName checks name against the platform's rules for app names,
+// which double as DNS labels under fly.dev.
+func ValidateAppName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("invalid app name: must not be empty")
+	case len(name) > maxAppNameLength:
+		return fmt.Errorf("invalid app name '%s': must be at most %d characters", name, maxAppNameLength)
+	case !appNamePattern.MatchString(name):
+		return fmt.Errorf("invalid app name '%s': must contain only lowercase letters, numbers and dashes, and not start or end with a dash", name)
+	}
+	return nil
+}
+
+// ValidateOrgSlug checks slug against the platform's rules for organization
+// slugs.
+func ValidateOrgSlug(slug string) error {
+	switch {
+	case slug == "":
+		return fmt.Errorf("invalid organization slug: must not be empty")
+	case !orgSlugPattern.MatchString(slug):
+		return fmt.Errorf("invalid organization slug '%s': must contain only lowercase letters, numbers and dashes, and not start or end with a dash", slug)
+	}
+	return nil
+}
+
+// ValidateRegionCode checks that code looks like a region code, such as
+// "ord". It doesn't check that the region exists; see PlatformRegions.
+func ValidateRegionCode(code string) error {
+	if !regionCodePattern.MatchString(code) {
+		return fmt.Errorf("invalid region code '%s': must be three lowercase letters", code)
+	}
+	return nil
+}
+
+// ValidateSecretKey checks that key can be used as a secret, which is
+// exposed to machines as an environment variable of the same name.
+func ValidateSecretKey(key string) error {
+	if !secretKeyPattern.MatchString(key) {
+		return fmt.Errorf("invalid secret name '%s': must contain only letters, numbers and underscores, and not start with a number", key)
+	}
+	return nil
+}
+
+// InvalidSecretsError is returned by ValidateSecrets for a batch of secrets
+// that fails validation.
+type InvalidSecretsError struct {
+	// InvalidKeys are the names that fail ValidateSecretKey, sorted.
+	InvalidKeys []string
+	// Size is the combined size, in bytes, of the batch's names and values.
+	Size int
+	// MaxSize is the size limit the batch was checked against, or zero.
+	MaxSize int
+}
+
+func (e *InvalidSecretsError) Error() string {
+	var problems []string
+	if len(e.InvalidKeys) > 0 {
+		problems = append(problems, fmt.Sprintf("invalid names %s: must contain only letters, numbers and underscores, and not start with a number", strings.Join(e.InvalidKeys, ", ")))
+	}
+	if e.MaxSize > 0 && e.Size > e.MaxSize {
+		problems = append(problems, fmt.Sprintf("%d bytes exceeds the limit of %d", e.Size, e.MaxSize))
+	}
+	return "invalid secrets: " + strings.Join(problems, "; ")
+}
+
+// ValidateSecrets checks every name in secrets with ValidateSecretKey and,
+// if maxSize is positive, that their combined size is at most maxSize bytes.
+// It reports all the problems at once, as an *InvalidSecretsError.
+func ValidateSecrets(secrets map[string]string, maxSize int) error {
+	e := &InvalidSecretsError{MaxSize: maxSize}
+	for k, v := range secrets {
+		if ValidateSecretKey(k) != nil {
+			e.InvalidKeys = append(e.InvalidKeys, k)
+		}
+		e.Size += len(k) + len(v)
+	}
+
+	if len(e.InvalidKeys) == 0 && (maxSize <= 0 || e.Size <= maxSize) {
+		return nil
+	}
+	sort.Strings(e.InvalidKeys)
+	return e
+}
+
+var (
+	operationVarsPattern = regexp.MustCompile(`^\s*(?:query|mutation|subscription)\s*\w*\s*\(([^)]*)\)`)
+	variablePattern      = regexp.MustCompile(`\$(\w+)\s*:\s*([\w\[\]!\s]+?)\s*(=|,|$)`)
+)
+
+// validateRequestVars checks that every non-null variable the operation in
+// req declares, without a default, has been given a value, so a missing one
+// fails before the request is sent.
+func validateRequestVars(req *graphql.Request) error {
+	m := operationVarsPattern.FindStringSubmatch(req.Query())
+	if m == nil {
+		return nil
+	}
+
+	vars := req.Vars()
+	var missing []string
+	for _, v := range variablePattern.FindAllStringSubmatch(m[1], -1) {
+		name, typ, next := v[1], v[2], v[3]
+		if !strings.HasSuffix(typ, "!") || next == "=" {
+			continue
+		}
+		if isNilVar(vars[name]) {
+			missing = append(missing, "$"+name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("missing value for required variable %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func isNilVar(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Interface, reflect.Slice:
+		return rv.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package fly
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidators(t *testing.T) {
+	type testcase struct {
+		name     string
+		validate func(string) error
+		value    string
+		valid    bool
+	}
+
+	cases := []testcase{
+		{name: "app name", validate: ValidateAppName, value: "my-app-2", valid: true},
+		{name: "app name uppercase", validate: ValidateAppName, value: "My-App", valid: false},
+		{name: "app name trailing dash", validate: ValidateAppName, value: "my-app-", valid: false},
+		{name: "app name too long", validate: ValidateAppName, value: "a123456789012345678901234567890123456789012345678901234567890123", valid: false},
+		{name: "app name empty", validate: ValidateAppName, value: "", valid: false},
+		{name: "org slug", validate: ValidateOrgSlug, value: "personal", valid: true},
+		{name: "org slug underscore", validate: ValidateOrgSlug, value: "my_org", valid: false},
+		{name: "region code", validate: ValidateRegionCode, value: "ord", valid: true},
+		{name: "region code uppercase", validate: ValidateRegionCode, value: "ORD", valid: false},
+		{name: "region code length", validate: ValidateRegionCode, value: "ords", valid: false},
+		{name: "secret key", validate: ValidateSecretKey, value: "DATABASE_URL", valid: true},
+		{name: "secret key leading underscore", validate: ValidateSecretKey, value: "_TOKEN", valid: true},
+		{name: "secret key leading digit", validate: ValidateSecretKey, value: "1PASSWORD", valid: false},
+		{name: "secret key dash", validate: ValidateSecretKey, value: "API-KEY", valid: false},
+	}
+
+	for _, tc := range cases {
+		err := tc.validate(tc.value)
+		if got := err == nil; got != tc.valid {
+			t.Errorf("%s, got '%v', want '%v' (%v)", tc.name, got, tc.valid, err)
+		}
+	}
+}
+
+func TestValidateSecrets(t *testing.T) {
+	if err := ValidateSecrets(map[string]string{"DATABASE_URL": "postgres://"}, 0); err != nil {
+		t.Errorf("valid secrets, got '%v', want nil", err)
+	}
+	if err := ValidateSecrets(map[string]string{"TOKEN": strings.Repeat("x", 1<<20)}, 0); err != nil {
+		t.Errorf("no size limit, got '%v', want nil", err)
+	}
+
+	const maxSize = 1024
+	err := ValidateSecrets(map[string]string{"API-KEY": "a", "1PASSWORD": "b", "TOKEN": strings.Repeat("x", maxSize)}, maxSize)
+
+	var invalid *InvalidSecretsError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("got '%v', want *InvalidSecretsError", err)
+	}
+	if got, want := strings.Join(invalid.InvalidKeys, ","), "1PASSWORD,API-KEY"; got != want {
+		t.Errorf("invalid keys, got '%v', want '%v'", got, want)
+	}
+	if got, want := invalid.Size, maxSize+23; got != want {
+		t.Errorf("size, got '%v', want '%v'", got, want)
+	}
+}
+
+func TestValidateRequestVars(t *testing.T) {
+	c := &Client{}
+
+	req := c.NewRequest(`
+		query($appName: String!, $limit: Int! = 10, $after: String, $input: CreateAppInput!) {
+			app(name: $appName) { id }
+		}
+	`)
+	req.Var("appName", "web")
+	req.Var("input", (*CreateAppInput)(nil))
+
+	err := validateRequestVars(req)
+	if err == nil || err.Error() != "missing value for required variable $input" {
+		t.Errorf("got '%v', want 'missing value for required variable $input'", err)
+	}
+
+	req.Var("input", CreateAppInput{Name: "web"})
+	if err := validateRequestVars(req); err != nil {
+		t.Errorf("got '%v', want nil", err)
+	}
+
+	if err := validateRequestVars(c.NewRequest(`query { viewer { id } }`)); err != nil {
+		t.Errorf("got '%v', want nil", err)
+	}
+
+	req = c.NewRequest(`query($ids: [String!]!) { nodes(ids: $ids) { id } }`)
+	req.Var("ids", []string(nil))
+	if err := validateRequestVars(req); err == nil || err.Error() != "missing value for required variable $ids" {
+		t.Errorf("got '%v', want 'missing value for required variable $ids'", err)
+	}
+	req.Var("ids", []string{})
+	if err := validateRequestVars(req); err != nil {
+		t.Errorf("empty list, got '%v', want nil", err)
+	}
+}
@@ -0,0 +1,85 @@
+package wg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+)
+
+// ForwardPort accepts connections on l and proxies each of them to
+// remoteAddr on the private network, until ctx is done or l fails. The
+// listener is closed when ForwardPort returns.
+func (t *Tunnel) ForwardPort(ctx context.Context, remoteAddr string, l net.Listener) error {
+	ctx, cancel := context.WithCancel(ctx)
+
+	var wg sync.WaitGroup
+	// Cancel first, so connections still being proxied are closed rather
+	// than waited on.
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+
+	for {
+		local, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			t.proxy(ctx, local, remoteAddr)
+		}()
+	}
+}
+
+func (t *Tunnel) proxy(ctx context.Context, local net.Conn, remoteAddr string) {
+	defer local.Close()
+
+	remote, err := t.DialContext(ctx, "tcp", remoteAddr)
+	if err != nil {
+		if t.OnForwardError != nil && ctx.Err() == nil {
+			t.OnForwardError(fmt.Errorf("wg: failed to forward connection from %s to %s: %w", local.RemoteAddr(), remoteAddr, err))
+		}
+		return
+	}
+	defer remote.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		<-ctx.Done()
+		local.Close()
+		remote.Close()
+	}()
+
+	done := make(chan struct{}, 2)
+	pipe := func(dst, src net.Conn) {
+		_, _ = io.Copy(dst, src)
+		if cw, ok := dst.(interface{ CloseWrite() error }); ok {
+			_ = cw.CloseWrite()
+		}
+		done <- struct{}{}
+	}
+	go pipe(remote, local)
+	go pipe(local, remote)
+
+	<-done
+	<-done
+}
@@ -0,0 +1,90 @@
+package wg
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+type dialerFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+func (f dialerFunc) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return f(ctx, network, address)
+}
+
+func TestForwardPort(t *testing.T) {
+	// The remote accepts connections and never closes them.
+	remote, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer remote.Close()
+	go func() {
+		for {
+			if _, err := remote.Accept(); err != nil {
+				return
+			}
+		}
+	}()
+
+	forwardErrs := make(chan error, 1)
+	tunnel := &Tunnel{
+		net: dialerFunc(func(ctx context.Context, network, address string) (net.Conn, error) {
+			if address == "unreachable:80" {
+				return nil, errors.New("no route to host")
+			}
+			var d net.Dialer
+			return d.DialContext(ctx, network, remote.Addr().String())
+		}),
+		OnForwardError: func(err error) { forwardErrs <- err },
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- tunnel.ForwardPort(context.Background(), "app.internal:80", l) }()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("hello"))
+	time.Sleep(50 * time.Millisecond)
+
+	// Failing the listener must stop ForwardPort even though the proxied
+	// connection is still open.
+	l.Close()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("ForwardPort did not return after its listener was closed")
+	}
+
+	l, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go tunnel.ForwardPort(context.Background(), "unreachable:80", l)
+
+	conn, err = net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	select {
+	case err := <-forwardErrs:
+		if err == nil {
+			t.Error("got nil, want a forwarding error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("dial error was not reported")
+	}
+}
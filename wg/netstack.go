@@ -0,0 +1,51 @@
+package wg
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"golang.zx2c4.com/wireguard/conn"
+	"golang.zx2c4.com/wireguard/device"
+	"golang.zx2c4.com/wireguard/tun/netstack"
+)
+
+// netstackMTU leaves room for WireGuard's overhead inside a 1500 byte MTU.
+const netstackMTU = 1420
+
+// Netstack is the NetFactory Connect uses by default. It runs wireguard-go
+// over a gVisor userspace network stack inside the process, so bringing a
+// tunnel up needs neither privileges nor a kernel interface.
+func Netstack(ctx context.Context, cfg *Config) (Net, error) {
+	var dns []netip.Addr
+	if cfg.DNS.IsValid() {
+		dns = append(dns, cfg.DNS)
+	}
+
+	tunDev, tnet, err := netstack.CreateNetTUN([]netip.Addr{cfg.LocalNetwork.Addr()}, dns, netstackMTU)
+	if err != nil {
+		return nil, fmt.Errorf("wg: failed to create netstack: %w", err)
+	}
+
+	dev := device.NewDevice(tunDev, conn.NewDefaultBind(), device.NewLogger(device.LogLevelSilent, ""))
+	if err := dev.IpcSet(cfg.UAPI()); err != nil {
+		dev.Close()
+		return nil, fmt.Errorf("wg: failed to configure device: %w", err)
+	}
+	if err := dev.Up(); err != nil {
+		dev.Close()
+		return nil, fmt.Errorf("wg: failed to bring device up: %w", err)
+	}
+
+	return &netstackNet{Net: tnet, dev: dev}, nil
+}
+
+type netstackNet struct {
+	*netstack.Net
+	dev *device.Device
+}
+
+func (n *netstackNet) Close() error {
+	n.dev.Close()
+	return nil
+}
@@ -0,0 +1,100 @@
+package wg
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"golang.zx2c4.com/wireguard/conn"
+	"golang.zx2c4.com/wireguard/device"
+	"golang.zx2c4.com/wireguard/tun/netstack"
+)
+
+func TestConnectNetstack(t *testing.T) {
+	localKey, err := NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gatewayKey, err := NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	localPub := localKey.Public()
+
+	// Stand in for the gateway with a second device that echoes TCP.
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := udp.LocalAddr().(*net.UDPAddr).Port
+	udp.Close()
+
+	gatewayAddr := netip.MustParseAddr("fdaa:0:1::1")
+	gatewayTun, gatewayNet, err := netstack.CreateNetTUN([]netip.Addr{gatewayAddr}, nil, netstackMTU)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gateway := device.NewDevice(gatewayTun, conn.NewDefaultBind(), device.NewLogger(device.LogLevelSilent, ""))
+	defer gateway.Close()
+	err = gateway.IpcSet(fmt.Sprintf("private_key=%s\nlisten_port=%d\npublic_key=%s\nallowed_ip=fdaa:0:1::2/128\n",
+		hex.EncodeToString(gatewayKey[:]), port, hex.EncodeToString(localPub[:])))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gateway.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := gatewayNet.ListenTCP(&net.TCPAddr{IP: gatewayAddr.AsSlice(), Port: 80})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		io.Copy(c, c)
+	}()
+
+	tunnel, err := Connect(context.Background(), &Config{
+		LocalPrivateKey: localKey,
+		LocalNetwork:    netip.MustParsePrefix("fdaa:0:1::2/120"),
+		RemotePublicKey: gatewayKey.Public(),
+		RemoteNetwork:   netip.MustParsePrefix("fdaa:0:1::/48"),
+		Endpoint:        netip.AddrPortFrom(netip.MustParseAddr("127.0.0.1"), uint16(port)),
+		KeepAlive:       time.Second,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tunnel.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	c, err := tunnel.DialContext(ctx, "tcp", "[fdaa:0:1::1]:80")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if _, err := c.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(c, buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "hello" {
+		t.Errorf("got '%s', want 'hello'", buf)
+	}
+}
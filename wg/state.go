@@ -0,0 +1,195 @@
+package wg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/graphql"
+)
+
+// PeerState is everything needed to bring a peer's tunnel back up without
+// creating a new peer.
+type PeerState struct {
+	Name       string `json:"name"`
+	Region     string `json:"region,omitempty"`
+	PrivateKey string `json:"private_key"`
+	Peer       struct {
+		Peerip     string `json:"peerip"`
+		Endpointip string `json:"endpointip"`
+		Pubkey     string `json:"pubkey"`
+	} `json:"peer"`
+}
+
+// Config derives the tunnel configuration from the saved state.
+func (s *PeerState) Config() (*Config, error) {
+	priv, err := ParsePrivateKey(s.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return ConfigFromPeer(&fly.CreatedWireGuardPeer{
+		Peerip:     s.Peer.Peerip,
+		Endpointip: s.Peer.Endpointip,
+		Pubkey:     s.Peer.Pubkey,
+	}, priv)
+}
+
+// matches reports whether peer, as the API knows it, is the peer the state
+// was saved for: same address, and the public key of the saved private key.
+func (s *PeerState) matches(peer *fly.WireGuardPeer) bool {
+	priv, err := ParsePrivateKey(s.PrivateKey)
+	if err != nil {
+		return false
+	}
+	return peer.Peerip == s.Peer.Peerip && peer.Pubkey == priv.Public().String()
+}
+
+func isNotFound(err error) bool {
+	var gqlErr *graphql.GraphQLError
+	return errors.Is(err, fly.ErrNotFound) || (errors.As(err, &gqlErr) && gqlErr.Extensions.Code == "NOT_FOUND")
+}
+
+// PeerStateStore persists peer state per organization slug.
+type PeerStateStore interface {
+	// Load returns the saved state for org, or nil if there is none.
+	Load(org string) (*PeerState, error)
+	Save(org string, state *PeerState) error
+	Delete(org string) error
+}
+
+// FileStore is a PeerStateStore keeping the state of every organization in a
+// single JSON file, readable only by its owner.
+type FileStore struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func (f *FileStore) Load(org string) (*PeerState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	states, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+	return states[org], nil
+}
+
+func (f *FileStore) Save(org string, state *PeerState) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	states, err := f.read()
+	if err != nil {
+		return err
+	}
+	states[org] = state
+	return f.write(states)
+}
+
+func (f *FileStore) Delete(org string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	states, err := f.read()
+	if err != nil {
+		return err
+	}
+	delete(states, org)
+	return f.write(states)
+}
+
+func (f *FileStore) read() (map[string]*PeerState, error) {
+	states := map[string]*PeerState{}
+
+	b, err := os.ReadFile(f.Path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return states, nil
+	case err != nil:
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, &states); err != nil {
+		return nil, fmt.Errorf("failed to decode peer state from %s: %w", f.Path, err)
+	}
+	return states, nil
+}
+
+func (f *FileStore) write(states map[string]*PeerState) error {
+	b, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0o700); err != nil {
+		return err
+	}
+
+	tmp := f.Path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.Path)
+}
+
+// ReestablishPeer returns the tunnel configuration of the peer saved in
+// store for org, as long as the peer still exists. Otherwise it creates a new
+// peer named name with opts, such as fly.WithPeerNetwork, and saves its
+// state. Pass the same opts every time so a recreated peer lands on the same
+// network as the one it replaces.
+func ReestablishPeer(ctx context.Context, client *fly.Client, store PeerStateStore, org *fly.Organization, region, name string, opts ...fly.WireGuardPeerOption) (*Config, error) {
+	state, err := store.Load(org.Slug)
+	if err != nil {
+		return nil, err
+	}
+
+	if state != nil {
+		peer, err := client.GetWireGuardPeer(ctx, org.Slug, state.Name)
+		switch {
+		case isNotFound(err):
+			peer = nil
+		case err != nil:
+			return nil, err
+		}
+		if peer != nil && state.matches(peer) {
+			return state.Config()
+		}
+
+		// The peer was removed or replaced out from under us. A replacement
+		// is removed too, so the new peer can take its name.
+		if peer != nil {
+			if err := client.RemoveWireGuardPeer(ctx, org, state.Name); err != nil {
+				return nil, err
+			}
+		}
+		if err := store.Delete(org.Slug); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg, peer, err := createPeer(ctx, client, org, region, name, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	state = &PeerState{
+		Name:       name,
+		Region:     region,
+		PrivateKey: cfg.LocalPrivateKey.String(),
+	}
+	state.Peer.Peerip = peer.Peerip
+	state.Peer.Endpointip = peer.Endpointip
+	state.Peer.Pubkey = peer.Pubkey
+
+	if err := store.Save(org.Slug, state); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
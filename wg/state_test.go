@@ -0,0 +1,110 @@
+package wg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	fly "github.com/superfly/fly-go"
+)
+
+func TestReestablishPeer(t *testing.T) {
+	gatewayKey, err := NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	storedKey, err := NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testcase struct {
+		name string
+		// serverPeer is the stored peer as the API knows it, or "" if it
+		// was deleted.
+		serverPeer string
+		want       []string
+	}
+
+	cases := []testcase{
+		{name: "deleted", serverPeer: "", want: []string{"get", "add"}},
+		{name: "replaced", serverPeer: otherKey.Public().String(), want: []string{"get", "remove", "add"}},
+		{name: "current", serverPeer: storedKey.Public().String(), want: []string{"get"}},
+	}
+
+	for _, tc := range cases {
+		var calls []string
+		taken := tc.serverPeer != ""
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Query     string
+				Variables struct {
+					Input fly.AddWireGuardPeerInput
+				}
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			w.Header().Set("Content-Type", "application/json")
+
+			switch {
+			case strings.Contains(body.Query, "wireGuardPeer(name"):
+				calls = append(calls, "get")
+				if tc.serverPeer == "" {
+					fmt.Fprint(w, `{"data":{"organization":null},"errors":[{"message":"Could not find peer","extensions":{"code":"NOT_FOUND"}}]}`)
+					return
+				}
+				fmt.Fprintf(w, `{"data":{"organization":{"wireGuardPeer":{"name":"agent","peerip":"fdaa:0:1:a7b:1::2","pubkey":"%s"}}}}`, tc.serverPeer)
+			case strings.Contains(body.Query, "removeWireGuardPeer"):
+				calls = append(calls, "remove")
+				taken = false
+				fmt.Fprint(w, `{"data":{"removeWireGuardPeer":{"organization":{"id":"org"}}}}`)
+			case strings.Contains(body.Query, "addWireGuardPeer"):
+				calls = append(calls, "add")
+				if n := body.Variables.Input.Network; n == nil || *n != "staging" {
+					t.Errorf("%s, got network '%v', want 'staging'", tc.name, n)
+				}
+				if taken {
+					fmt.Fprint(w, `{"data":null,"errors":[{"message":"name has already been taken"}]}`)
+					return
+				}
+				fmt.Fprintf(w, `{"data":{"addWireGuardPeer":{"peerip":"fdaa:0:1:a7b:1::3","endpointip":"1.2.3.4","pubkey":"%s"}}}`, gatewayKey.Public())
+			}
+		}))
+
+		store := &FileStore{Path: filepath.Join(t.TempDir(), "peers.json")}
+		state := &PeerState{Name: "agent", PrivateKey: storedKey.String()}
+		state.Peer.Peerip = "fdaa:0:1:a7b:1::2"
+		state.Peer.Endpointip = "1.2.3.4"
+		state.Peer.Pubkey = gatewayKey.Public().String()
+		if err := store.Save("personal", state); err != nil {
+			t.Fatal(err)
+		}
+
+		client := fly.NewClientFromOptions(fly.ClientOptions{BaseURL: srv.URL})
+		org := &fly.Organization{ID: "org", Slug: "personal"}
+
+		cfg, err := ReestablishPeer(context.Background(), client, store, org, "ord", "agent", fly.WithPeerNetwork("staging"))
+		srv.Close()
+		if err != nil {
+			t.Errorf("%s, unexpected error: %v", tc.name, err)
+			continue
+		}
+		if !slices.Equal(calls, tc.want) {
+			t.Errorf("%s, got '%v', want '%v'", tc.name, calls, tc.want)
+		}
+		wantReused := tc.serverPeer == storedKey.Public().String()
+		if reused := cfg.LocalPrivateKey == storedKey; reused != wantReused {
+			t.Errorf("%s, reused stored key, got '%v', want '%v'", tc.name, reused, wantReused)
+		}
+	}
+}
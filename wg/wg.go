@@ -0,0 +1,254 @@
+// Package wg connects programs to an organization's private network over a
+// userspace WireGuard tunnel.
+//
+// The package creates peers through the API and derives the tunnel
+// configuration from them. By default the tunnel runs in wireguard-go's
+// gVisor-based netstack, see Netstack, so no TUN device or privileges are
+// needed. Callers with their own network stack can supply it through a
+// NetFactory instead.
+package wg
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"strings"
+	"time"
+
+	fly "github.com/superfly/fly-go"
+	"golang.org/x/crypto/curve25519"
+)
+
+const (
+	// EndpointPort is the port WireGuard gateways listen on.
+	EndpointPort = 51820
+
+	defaultKeepAlive = 15 * time.Second
+)
+
+// PrivateKey is a Curve25519 WireGuard private key.
+type PrivateKey [32]byte
+
+// PublicKey is a Curve25519 WireGuard public key.
+type PublicKey [32]byte
+
+// NewPrivateKey generates a new random private key.
+func NewPrivateKey() (PrivateKey, error) {
+	var k PrivateKey
+	if _, err := rand.Read(k[:]); err != nil {
+		return k, err
+	}
+	// clamp, see https://cr.yp.to/ecdh.html
+	k[0] &= 248
+	k[31] = (k[31] & 127) | 64
+	return k, nil
+}
+
+// Public returns the public key corresponding to k.
+func (k PrivateKey) Public() PublicKey {
+	var pub PublicKey
+	curve25519.ScalarBaseMult((*[32]byte)(&pub), (*[32]byte)(&k))
+	return pub
+}
+
+func (k PrivateKey) String() string { return base64.StdEncoding.EncodeToString(k[:]) }
+func (k PublicKey) String() string  { return base64.StdEncoding.EncodeToString(k[:]) }
+
+// ParsePrivateKey parses a base64 encoded private key.
+func ParsePrivateKey(s string) (PrivateKey, error) {
+	var k PrivateKey
+	return k, parseKey(s, k[:])
+}
+
+// ParsePublicKey parses a base64 encoded public key.
+func ParsePublicKey(s string) (PublicKey, error) {
+	var k PublicKey
+	return k, parseKey(s, k[:])
+}
+
+func parseKey(s string, dst []byte) error {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("invalid key: %w", err)
+	}
+	if len(b) != len(dst) {
+		return fmt.Errorf("invalid key: expected %d bytes, got %d", len(dst), len(b))
+	}
+	copy(dst, b)
+	return nil
+}
+
+// Config describes one end of a tunnel into an organization's private
+// network.
+type Config struct {
+	LocalPrivateKey PrivateKey
+	// LocalNetwork is the address assigned to the peer.
+	LocalNetwork netip.Prefix
+
+	RemotePublicKey PublicKey
+	// RemoteNetwork is the organization's private network, routed through
+	// the tunnel.
+	RemoteNetwork netip.Prefix
+	Endpoint      netip.AddrPort
+
+	// DNS is the organization's internal DNS server.
+	DNS       netip.Addr
+	KeepAlive time.Duration
+}
+
+// ConfigFromPeer derives the tunnel configuration for a peer created with
+// privateKey.
+func ConfigFromPeer(peer *fly.CreatedWireGuardPeer, privateKey PrivateKey) (*Config, error) {
+	local, err := netip.ParseAddr(peer.Peerip)
+	if err != nil {
+		return nil, fmt.Errorf("invalid peer IP '%s': %w", peer.Peerip, err)
+	}
+	if !local.Is6() {
+		return nil, fmt.Errorf("invalid peer IP '%s': expected an IPv6 address", peer.Peerip)
+	}
+
+	endpoint, err := netip.ParseAddr(peer.Endpointip)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint IP '%s': %w", peer.Endpointip, err)
+	}
+
+	remoteKey, err := ParsePublicKey(peer.Pubkey)
+	if err != nil {
+		return nil, err
+	}
+
+	org := netip.PrefixFrom(local, 48).Masked()
+
+	dns := org.Addr().As16()
+	dns[15] = 3
+
+	return &Config{
+		LocalPrivateKey: privateKey,
+		LocalNetwork:    netip.PrefixFrom(local, 120),
+		RemotePublicKey: remoteKey,
+		RemoteNetwork:   org,
+		Endpoint:        netip.AddrPortFrom(endpoint, EndpointPort),
+		DNS:             netip.AddrFrom16(dns),
+		KeepAlive:       defaultKeepAlive,
+	}, nil
+}
+
+// String renders the configuration in the wg-quick file format.
+func (c *Config) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[Interface]\n")
+	fmt.Fprintf(&b, "PrivateKey = %s\n", c.LocalPrivateKey)
+	fmt.Fprintf(&b, "Address = %s\n", c.LocalNetwork)
+	fmt.Fprintf(&b, "DNS = %s\n", c.DNS)
+	fmt.Fprintf(&b, "\n[Peer]\n")
+	fmt.Fprintf(&b, "PublicKey = %s\n", c.RemotePublicKey)
+	fmt.Fprintf(&b, "AllowedIPs = %s\n", c.RemoteNetwork)
+	fmt.Fprintf(&b, "Endpoint = %s\n", c.Endpoint)
+	fmt.Fprintf(&b, "PersistentKeepalive = %d\n", int(c.KeepAlive.Seconds()))
+	return b.String()
+}
+
+// UAPI renders the configuration in the cross-platform userspace API format
+// accepted by wireguard-go's Device.IpcSet.
+func (c *Config) UAPI() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "private_key=%s\n", hex.EncodeToString(c.LocalPrivateKey[:]))
+	fmt.Fprintf(&b, "public_key=%s\n", hex.EncodeToString(c.RemotePublicKey[:]))
+	fmt.Fprintf(&b, "endpoint=%s\n", c.Endpoint)
+	fmt.Fprintf(&b, "allowed_ip=%s\n", c.RemoteNetwork)
+	fmt.Fprintf(&b, "persistent_keepalive_interval=%d\n", int(c.KeepAlive.Seconds()))
+	return b.String()
+}
+
+// Net is a userspace network stack running a WireGuard tunnel. A Net that
+// also implements io.Closer is closed by Tunnel.Close.
+type Net interface {
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// NetFactory brings up a tunnel for cfg. Netstack is the default; other
+// implementations can, for example, run the tunnel through a kernel
+// interface instead.
+type NetFactory func(ctx context.Context, cfg *Config) (Net, error)
+
+// Tunnel is an established connection to an organization's private network.
+type Tunnel struct {
+	Config *Config
+	net    Net
+
+	// OnForwardError, when set, is called when ForwardPort can't reach the
+	// remote address for a connection it accepted. The connection is
+	// closed either way.
+	OnForwardError func(error)
+}
+
+// Connect brings up a tunnel for cfg using newNet, or a userspace Netstack
+// tunnel when newNet is nil.
+func Connect(ctx context.Context, cfg *Config, newNet NetFactory) (*Tunnel, error) {
+	if newNet == nil {
+		newNet = Netstack
+	}
+
+	n, err := newNet(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("wg: failed to bring up tunnel: %w", err)
+	}
+	return &Tunnel{Config: cfg, net: n}, nil
+}
+
+// DialContext connects to address on the private network.
+func (t *Tunnel) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return t.net.DialContext(ctx, network, address)
+}
+
+// Close tears the tunnel down. Nets that hold a device implement io.Closer
+// to release it; for others Close does nothing.
+func (t *Tunnel) Close() error {
+	if c, ok := t.net.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Resolver returns a resolver that looks names up with the organization's
+// internal DNS server, over the tunnel.
+func (t *Tunnel) Resolver() *net.Resolver {
+	dns := netip.AddrPortFrom(t.Config.DNS, 53).String()
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return t.DialContext(ctx, network, dns)
+		},
+	}
+}
+
+// CreatePeer generates a key pair, registers a new peer with the API and
+// returns the tunnel configuration for it. opts are passed on to
+// fly.Client.CreateWireGuardPeer.
+func CreatePeer(ctx context.Context, client *fly.Client, org *fly.Organization, region, name string, opts ...fly.WireGuardPeerOption) (*Config, error) {
+	cfg, _, err := createPeer(ctx, client, org, region, name, opts...)
+	return cfg, err
+}
+
+func createPeer(ctx context.Context, client *fly.Client, org *fly.Organization, region, name string, opts ...fly.WireGuardPeerOption) (*Config, *fly.CreatedWireGuardPeer, error) {
+	priv, err := NewPrivateKey()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	peer, err := client.CreateWireGuardPeer(ctx, org, region, name, priv.Public().String(), opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cfg, err := ConfigFromPeer(peer, priv)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, peer, nil
+}
@@ -0,0 +1,47 @@
+package wg
+
+import (
+	"testing"
+
+	fly "github.com/superfly/fly-go"
+)
+
+func TestConfigFromPeer(t *testing.T) {
+	priv, err := NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote, err := NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := ConfigFromPeer(&fly.CreatedWireGuardPeer{
+		Peerip:     "fdaa:0:1234:a7b:1f60:0:a:102",
+		Endpointip: "1.2.3.4",
+		Pubkey:     remote.Public().String(),
+	}, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(name, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("%s, got '%v', want '%v'", name, got, want)
+		}
+	}
+
+	check("local network", cfg.LocalNetwork.String(), "fdaa:0:1234:a7b:1f60:0:a:102/120")
+	check("remote network", cfg.RemoteNetwork.String(), "fdaa:0:1234::/48")
+	check("dns", cfg.DNS.String(), "fdaa:0:1234::3")
+	check("endpoint", cfg.Endpoint.String(), "1.2.3.4:51820")
+
+	if cfg.RemotePublicKey != remote.Public() {
+		t.Error("remote public key mismatch")
+	}
+
+	if _, err := ConfigFromPeer(&fly.CreatedWireGuardPeer{Peerip: "10.0.0.1", Endpointip: "1.2.3.4", Pubkey: remote.Public().String()}, priv); err == nil {
+		t.Error("want error for IPv4 peer IP")
+	}
+}